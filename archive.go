@@ -0,0 +1,150 @@
+package httpretty
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+)
+
+// ArchiveFormatter lists the entries of zip and tar archives, including gzip compressed tarballs,
+// rather than skipping them as binary data.
+//
+// Each entry is printed with its mode, size, modification time, and name. Archives larger than MaxSize,
+// or than Logger.MaxRequestBody and Logger.MaxResponseBody if smaller, are skipped: those with a known
+// Content-Length aren't read at all, and others aren't read past the limit.
+type ArchiveFormatter struct {
+	// MaxEntries to list. Defaults to 100.
+	MaxEntries int
+
+	// MaxSize of archives to list, in bytes. Defaults to 10 MiB.
+	MaxSize int64
+}
+
+// Match zip, tar, and gzip media types.
+func (a *ArchiveFormatter) Match(mediatype string) bool {
+	switch mediatype {
+	case "application/zip", "application/x-zip-compressed",
+		"application/x-tar", "application/x-gtar",
+		"application/gzip", "application/x-gzip", "application/x-compressed-tar":
+		return true
+	}
+	return false
+}
+
+// AcceptsBinary data.
+func (a *ArchiveFormatter) AcceptsBinary() bool {
+	return true
+}
+
+// Format archives.
+func (a *ArchiveFormatter) Format(w io.Writer, src []byte) error {
+	var (
+		buf bytes.Buffer
+		err error
+	)
+	switch {
+	case bytes.HasPrefix(src, []byte("PK\x03\x04")), bytes.HasPrefix(src, []byte("PK\x05\x06")):
+		err = a.listZip(&buf, src)
+	case bytes.HasPrefix(src, []byte("\x1f\x8b")):
+		err = a.listGzip(&buf, src)
+	default:
+		err = a.listTar(&buf, "tar archive", bytes.NewReader(src))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func (a *ArchiveFormatter) maxBodySize() int64 {
+	if a.MaxSize <= 0 {
+		return 10 << 20
+	}
+	return a.MaxSize
+}
+
+func (a *ArchiveFormatter) maxEntries() int {
+	if a.MaxEntries <= 0 {
+		return 100
+	}
+	return a.MaxEntries
+}
+
+func (a *ArchiveFormatter) listZip(buf *bytes.Buffer, src []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(src), int64(len(src)))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "zip archive with %d entries\n", len(zr.File))
+	for i, f := range zr.File {
+		if i == a.maxEntries() {
+			fmt.Fprintf(buf, "... %d more entries\n", len(zr.File)-i)
+			break
+		}
+		writeArchiveEntry(buf, f.Mode(), int64(f.UncompressedSize64), f.Modified, f.Name, "")
+	}
+	return nil
+}
+
+func (a *ArchiveFormatter) listGzip(buf *bytes.Buffer, src []byte) error {
+	zr, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	// peek a tar header, which is 512 bytes long, before deciding how to list it.
+	header := make([]byte, 512)
+	n, err := io.ReadFull(zr, header)
+	header = header[:n]
+	if _, terr := tar.NewReader(bytes.NewReader(header)).Next(); terr != nil || err != nil && err != io.ErrUnexpectedEOF {
+		buf.WriteString("gzip compressed data")
+		if zr.Name != "" {
+			fmt.Fprintf(buf, ": %s", zr.Name)
+		}
+		buf.WriteByte('\n')
+		return nil
+	}
+	return a.listTar(buf, "gzip compressed tar archive", io.MultiReader(bytes.NewReader(header), zr))
+}
+
+func (a *ArchiveFormatter) listTar(buf *bytes.Buffer, title string, r io.Reader) error {
+	tr := tar.NewReader(r)
+	var listed bytes.Buffer
+	for i := 0; ; i++ {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if i == 0 {
+				return err
+			}
+			fmt.Fprintf(&listed, "cannot read entry: %v\n", err)
+			break
+		}
+		if i == a.maxEntries() {
+			// don't read the rest of the archive.
+			listed.WriteString("... more entries not listed\n")
+			break
+		}
+		writeArchiveEntry(&listed, h.FileInfo().Mode(), h.Size, h.ModTime, h.Name, h.Linkname)
+	}
+	fmt.Fprintf(buf, "%s\n", title)
+	buf.Write(listed.Bytes())
+	return nil
+}
+
+func writeArchiveEntry(buf *bytes.Buffer, mode fs.FileMode, size int64, modified time.Time, name, link string) {
+	fmt.Fprintf(buf, "%s %10d %s %s", mode, size, modified.UTC().Format(time.DateTime), name)
+	if link != "" {
+		fmt.Fprintf(buf, " -> %s", link)
+	}
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,115 @@
+package httpretty
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+	"time"
+)
+
+var archiveTime = time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+func testZip() []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"README.md", "cmd/main.go", "go.mod"} {
+		fh := &zip.FileHeader{Name: name, Modified: archiveTime, Method: zip.Deflate}
+		fh.SetMode(0o644)
+		w, _ := zw.CreateHeader(fh)
+		_, _ = w.Write([]byte("content of " + name))
+	}
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func testTarGz() []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	_ = tw.WriteHeader(&tar.Header{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: archiveTime})
+	_ = tw.WriteHeader(&tar.Header{Name: "bin/tool", Mode: 0o755, Size: 4, ModTime: archiveTime})
+	_, _ = tw.Write([]byte("tool"))
+	_ = tw.WriteHeader(&tar.Header{Name: "bin/latest", Typeflag: tar.TypeSymlink, Linkname: "tool", Mode: 0o777, ModTime: archiveTime})
+	_ = tw.Close()
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestArchiveFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc       string
+		maxEntries int
+		src        []byte
+		want       string
+	}{
+		{
+			desc: "zip",
+			src:  testZip(),
+			want: `zip archive with 3 entries
+-rw-r--r--         20 2024-03-01 12:30:00 README.md
+-rw-r--r--         22 2024-03-01 12:30:00 cmd/main.go
+-rw-r--r--         17 2024-03-01 12:30:00 go.mod`,
+		},
+		{
+			desc:       "zip capped",
+			maxEntries: 1,
+			src:        testZip(),
+			want: `zip archive with 3 entries
+-rw-r--r--         20 2024-03-01 12:30:00 README.md
+... 2 more entries`,
+		},
+		{
+			desc: "tar.gz",
+			src:  testTarGz(),
+			want: `gzip compressed tar archive
+drwxr-xr-x          0 2024-03-01 12:30:00 bin/
+-rwxr-xr-x          4 2024-03-01 12:30:00 bin/tool
+Lrwxrwxrwx          0 2024-03-01 12:30:00 bin/latest -> tool`,
+		},
+		{
+			desc:       "tar.gz capped",
+			maxEntries: 2,
+			src:        testTarGz(),
+			want: `gzip compressed tar archive
+drwxr-xr-x          0 2024-03-01 12:30:00 bin/
+-rwxr-xr-x          4 2024-03-01 12:30:00 bin/tool
+... more entries not listed`,
+		},
+		{
+			desc: "gzip",
+			src:  gzipBytes("not a tarball"),
+			want: "gzip compressed data",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			f := &ArchiveFormatter{MaxEntries: tc.maxEntries}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, tc.src); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestArchiveFormatterInvalid(t *testing.T) {
+	t.Parallel()
+	f := &ArchiveFormatter{}
+	if !f.Match("application/zip") || !f.Match("application/x-gzip") || !f.AcceptsBinary() {
+		t.Error("expected ArchiveFormatter to match archives binary data")
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, testZip()[:40]); err == nil {
+		t.Error("expected error formatting truncated zip archive")
+	}
+	if err := f.Format(&buf, []byte("not an archive")); err == nil {
+		t.Error("expected error formatting invalid archive")
+	}
+}
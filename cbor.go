@@ -0,0 +1,316 @@
+package httpretty
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// CBORFormatter formats CBOR (RFC 8949) documents and sequences as a JSON-like tree.
+//
+// Values are indented like JSONFormatter does. Byte strings, tags, simple values, and map keys
+// that aren't strings are annotated with their type. Date/time tags are printed in RFC 3339
+// format, and bignums are decoded.
+type CBORFormatter struct{}
+
+// Match CBOR media types.
+func (c *CBORFormatter) Match(mediatype string) bool {
+	return mediatype == "application/cbor" || mediatype == "application/cbor-seq" ||
+		strings.HasSuffix(mediatype, "+cbor")
+}
+
+// AcceptsBinary data.
+func (c *CBORFormatter) AcceptsBinary() bool {
+	return true
+}
+
+// Format CBOR content.
+func (c *CBORFormatter) Format(w io.Writer, src []byte) error {
+	var nodes []treeNode
+	for len(src) != 0 {
+		d := cborDecoder{b: src}
+		n, err := d.decode(0)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+		src = d.b
+	}
+	var buf bytes.Buffer
+	writeTrees(&buf, nodes)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+const (
+	cborUint = iota
+	cborNegative
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// cborIndefinite is the additional information of indefinite-length items.
+const cborIndefinite = 31
+
+var (
+	errCBORTruncated = errors.New("cbor: unexpected end of data")
+	errCBORBreak     = errors.New("cbor: unexpected break")
+)
+
+type cborDecoder struct {
+	b []byte
+}
+
+func (d *cborDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)) {
+		return nil, errCBORTruncated
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v, nil
+}
+
+// head reads the major type, additional information, and argument of the next item.
+func (d *cborDecoder) head() (major, info byte, arg uint64, err error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		v, err := d.read(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range v {
+			arg = arg<<8 | uint64(c)
+		}
+		return major, info, arg, nil
+	case info == cborIndefinite:
+		return major, info, 0, nil
+	}
+	return 0, 0, 0, fmt.Errorf("cbor: invalid additional information %d", info)
+}
+
+func (d *cborDecoder) decode(depth int) (treeNode, error) {
+	if depth > treeMaxDepth {
+		return treeNode{}, errors.New("cbor: exceeded maximum nesting depth")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return treeNode{}, err
+	}
+	if info == cborIndefinite {
+		switch major {
+		case cborBytes, cborText:
+			return d.indefiniteString(major)
+		case cborArray, cborMap:
+			return d.indefiniteCollection(major, depth)
+		case cborSimple:
+			return treeNode{}, errCBORBreak
+		}
+		return treeNode{}, fmt.Errorf("cbor: invalid indefinite length for major type %d", major)
+	}
+	switch major {
+	case cborUint:
+		return treeUint(arg), nil
+	case cborNegative:
+		if arg > math.MaxInt64 {
+			n := new(big.Int).SetUint64(arg)
+			n.Neg(n.Add(n, big.NewInt(1)))
+			return treeNode{value: n.String(), typ: "integer"}, nil
+		}
+		return treeInt(-1 - int64(arg)), nil
+	case cborBytes:
+		b, err := d.read(arg)
+		if err != nil {
+			return treeNode{}, err
+		}
+		return treeBinary(b, "bytes"), nil
+	case cborText:
+		b, err := d.read(arg)
+		if err != nil {
+			return treeNode{}, err
+		}
+		return treeString(string(b)), nil
+	case cborArray, cborMap:
+		return d.collection(major, arg, depth)
+	case cborTag:
+		return d.tag(arg, depth)
+	}
+	return d.simple(info, arg)
+}
+
+func (d *cborDecoder) collection(major byte, n uint64, depth int) (treeNode, error) {
+	kind := treeArray
+	if major == cborMap {
+		kind = treeMap
+		n *= 2
+	}
+	// each item takes at least a byte, so don't trust the length for allocating.
+	if n > uint64(len(d.b)) {
+		return treeNode{}, errCBORTruncated
+	}
+	node := treeNode{kind: kind, items: make([]treeNode, 0, n)}
+	for i := uint64(0); i < n; i++ {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return treeNode{}, err
+		}
+		node.items = append(node.items, item)
+	}
+	return node, nil
+}
+
+func (d *cborDecoder) isBreak() bool {
+	if len(d.b) != 0 && d.b[0] == 0xff {
+		d.b = d.b[1:]
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) indefiniteCollection(major byte, depth int) (treeNode, error) {
+	node := treeNode{kind: treeArray, note: "indefinite length"}
+	if major == cborMap {
+		node.kind = treeMap
+	}
+	for !d.isBreak() {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return treeNode{}, err
+		}
+		node.items = append(node.items, item)
+	}
+	if node.kind == treeMap && len(node.items)%2 != 0 {
+		return treeNode{}, errors.New("cbor: map is missing a value")
+	}
+	return node, nil
+}
+
+func (d *cborDecoder) indefiniteString(major byte) (treeNode, error) {
+	var chunks []byte
+	for !d.isBreak() {
+		m, info, n, err := d.head()
+		if err != nil {
+			return treeNode{}, err
+		}
+		if m != major || info == cborIndefinite {
+			return treeNode{}, errors.New("cbor: invalid chunk of indefinite-length string")
+		}
+		b, err := d.read(n)
+		if err != nil {
+			return treeNode{}, err
+		}
+		chunks = append(chunks, b...)
+	}
+	if major == cborText {
+		return treeString(string(chunks)), nil
+	}
+	return treeBinary(chunks, "bytes"), nil
+}
+
+func (d *cborDecoder) tag(number uint64, depth int) (treeNode, error) {
+	if number == 2 || number == 3 {
+		saved := d.b
+		if major, info, n, err := d.head(); err == nil && major == cborBytes && info != cborIndefinite {
+			if b, err := d.read(n); err == nil {
+				return cborBignum(number, b), nil
+			}
+		}
+		d.b = saved
+	}
+	n, err := d.decode(depth + 1)
+	if err != nil {
+		return treeNode{}, err
+	}
+	note := fmt.Sprintf("tag %d", number)
+	switch number {
+	case 0:
+		note += ": date/time"
+	case 1:
+		if v, ok := cborEpoch(n); ok {
+			note += ": " + treeTime(v)
+		}
+	case 55799:
+		note += ": self-described CBOR"
+	}
+	if n.note != "" {
+		note = n.note + ", " + note
+	}
+	n.note = note
+	return n, nil
+}
+
+func cborEpoch(n treeNode) (time.Time, bool) {
+	if n.kind != treeScalar || (n.typ != "integer" && n.typ != "float") {
+		return time.Time{}, false
+	}
+	var f float64
+	if _, err := fmt.Sscan(n.value, &f); err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+func cborBignum(number uint64, b []byte) treeNode {
+	v := new(big.Int).SetBytes(b)
+	if number == 3 {
+		v.Neg(v.Add(v, big.NewInt(1)))
+	}
+	return treeNode{value: v.String(), typ: "integer", note: fmt.Sprintf("tag %d: bignum", number)}
+}
+
+func (d *cborDecoder) simple(info byte, arg uint64) (treeNode, error) {
+	switch info {
+	case 20, 21:
+		return treeBool(info == 21), nil
+	case 22:
+		return treeNull(), nil
+	case 23:
+		return treeNode{value: "undefined", typ: "undefined"}, nil
+	case 25:
+		return treeFloat(float16(uint16(arg)), 32), nil
+	case 26:
+		return treeFloat(float64(math.Float32frombits(uint32(arg))), 32), nil
+	case 27:
+		return treeFloat(math.Float64frombits(arg), 64), nil
+	}
+	return treeNode{value: fmt.Sprintf("simple(%d)", arg), typ: "simple"}, nil
+}
+
+// float16 converts a IEEE 754 half-precision float.
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		v = -v
+	}
+	return v
+}
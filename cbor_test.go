@@ -0,0 +1,78 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCBORFormatter(t *testing.T) {
+	t.Parallel()
+	src := []byte{
+		0xa6,                                                                           // map of 6
+		0x64, 'n', 'a', 'm', 'e', 0x7f, 0x62, 'g', 'o', 0x64, 'p', 'h', 'e', 'r', 0xff, // "name": indefinite "gopher"
+		0x01, 0x38, 0x63, // 1: -100
+		0x62, 'a', 't', 0xc1, 0x1a, 0x65, 0x53, 0xf1, 0x00, // "at": tag 1 epoch
+		0x63, 'r', 'a', 'w', 0x42, 0x01, 0x02, // "raw": h'0102'
+		0x82, 0x01, 0x02, 0x9f, 0xf9, 0x3c, 0x00, 0xf7, 0xff, // [1, 2]: [_ 1.0, undefined]
+		0x63, 'b', 'i', 'g', 0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0, // "big": 2^64
+	}
+	f := &CBORFormatter{}
+	if !f.Match("application/cbor") || !f.Match("application/senml+cbor") || !f.AcceptsBinary() {
+		t.Error("expected CBORFormatter to match CBOR binary data")
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, src); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `{
+    "name": "gopher",
+    1 (integer key): -100,
+    "at": 1700000000 (tag 1: 2023-11-14T22:13:20Z),
+    "raw": 0x0102 (bytes, 2 bytes),
+    [1,2] (array key): [
+        1,
+        undefined
+    ] (indefinite length),
+    "big": 18446744073709551616 (tag 2: bignum)
+}`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+}
+
+func TestCBORFormatterSequence(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := (&CBORFormatter{}).Format(&buf, []byte{0xf5, 0x20, 0xf9, 0x7c, 0x00, 0xf0}); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := "true\n-1\nInfinity\nsimple(16)"
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %q, wanted %q", got, want)
+	}
+}
+
+func TestCBORFormatterInvalid(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		src  []byte
+		want string
+	}{
+		{"truncated", []byte{0x64, 'a'}, "cbor: unexpected end of data"},
+		{"break", []byte{0xff}, "cbor: unexpected break"},
+		{"additional information", []byte{0x1c}, "cbor: invalid additional information 28"},
+		{"indefinite integer", []byte{0x1f}, "cbor: invalid indefinite length for major type 0"},
+		{"chunk", []byte{0x7f, 0x41, 0x00, 0xff}, "cbor: invalid chunk of indefinite-length string"},
+		{"depth", bytes.Repeat([]byte{0x81}, 100), "cbor: exceeded maximum nesting depth"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := (&CBORFormatter{}).Format(&buf, tc.src); err == nil || err.Error() != tc.want {
+				t.Errorf("got format error = %v, wanted %v", err, tc.want)
+			}
+		})
+	}
+}
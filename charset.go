@@ -0,0 +1,142 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/henvic/httpretty/internal/color"
+)
+
+// CharsetDecoder transcodes bodies in a given charset to UTF-8, so they can be printed.
+//
+// Bodies are only transcoded for printing: the bytes read by the caller are left untouched.
+type CharsetDecoder interface {
+	Decode(src []byte) ([]byte, error)
+}
+
+// CharsetDecoderFunc is an adapter to allow the use of ordinary functions as a CharsetDecoder.
+type CharsetDecoderFunc func(src []byte) ([]byte, error)
+
+// Decode calls f(src).
+func (f CharsetDecoderFunc) Decode(src []byte) ([]byte, error) {
+	return f(src)
+}
+
+// defaultCharsetDecoders are used unless replaced or removed with Logger.SetCharsetDecoder.
+//
+// Like web browsers do, ISO-8859-1 is decoded as its Windows-1252 superset, which assigns
+// printable characters rather than C1 control codes to the 0x80-0x9F range.
+var defaultCharsetDecoders = map[string]CharsetDecoder{
+	"utf-16":       CharsetDecoderFunc(decodeUTF16BE),
+	"utf-16be":     CharsetDecoderFunc(decodeUTF16BE),
+	"utf-16le":     CharsetDecoderFunc(decodeUTF16LE),
+	"iso-8859-1":   CharsetDecoderFunc(decodeWindows1252),
+	"iso8859-1":    CharsetDecoderFunc(decodeWindows1252),
+	"latin1":       CharsetDecoderFunc(decodeWindows1252),
+	"l1":           CharsetDecoderFunc(decodeWindows1252),
+	"windows-1252": CharsetDecoderFunc(decodeWindows1252),
+	"cp1252":       CharsetDecoderFunc(decodeWindows1252),
+}
+
+var errOddUTF16 = errors.New("odd number of bytes for UTF-16")
+
+func decodeUTF16(src []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(src)%2 != 0 {
+		return nil, errOddUTF16
+	}
+	u := make([]uint16, 0, len(src)/2)
+	for i := 0; i < len(src); i += 2 {
+		u = append(u, order.Uint16(src[i:]))
+	}
+	return []byte(string(utf16.Decode(u))), nil
+}
+
+func decodeUTF16BE(src []byte) ([]byte, error) {
+	return decodeUTF16(src, binary.BigEndian)
+}
+
+func decodeUTF16LE(src []byte) ([]byte, error) {
+	return decodeUTF16(src, binary.LittleEndian)
+}
+
+// windows1252 maps the 0x80-0x9F range, which differs from ISO-8859-1.
+// Undefined positions are mapped to the C1 control code of the same value.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(src []byte) ([]byte, error) {
+	dst := make([]byte, 0, len(src))
+	for _, c := range src {
+		r := rune(c)
+		if c >= 0x80 && c <= 0x9f {
+			r = windows1252[c-0x80]
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+	return dst, nil
+}
+
+// decodeCharset transcodes a body to UTF-8 using its byte order mark, or the charset parameter of its Content-Type.
+// Byte order marks are only looked for on textual media types, as binary data might start with the same bytes.
+// If the body cannot be transcoded, the reason is printed, and the body is returned unchanged.
+func (p *printer) decodeCharset(mediatype, charset string, body []byte) []byte {
+	switch {
+	case !isTextMediatype(mediatype):
+	case bytes.HasPrefix(body, []byte{0xEF, 0xBB, 0xBF}):
+		return body[3:]
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		body, charset = body[2:], "utf-16be"
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		body, charset = body[2:], "utf-16le"
+	}
+	switch charset = strings.ToLower(charset); charset {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return body
+	}
+	d := p.logger.getCharsetDecoder(charset)
+	if d == nil {
+		p.printf("* body charset %s cannot be decoded\n", charset)
+		return body
+	}
+	decoded, err := p.safeDecodeCharset(d, body)
+	if err != nil {
+		p.printf("* cannot decode %s body: %v\n", charset, p.format(color.FgRed, err.Error()))
+		return body
+	}
+	return decoded
+}
+
+// textMediatypes are textual media types that aren't text/*, +json, or +xml.
+var textMediatypes = map[string]struct{}{
+	"application/xml":                   {},
+	"application/javascript":            {},
+	"application/ecmascript":            {},
+	"application/graphql":               {},
+	"application/x-www-form-urlencoded": {},
+	"application/x-ndjson":              {},
+}
+
+// isTextMediatype checks if a media type is textual.
+func isTextMediatype(mediatype string) bool {
+	if strings.HasPrefix(mediatype, "text/") || strings.HasSuffix(mediatype, "+xml") || jsonTypeRE.MatchString(mediatype) {
+		return true
+	}
+	_, ok := textMediatypes[mediatype]
+	return ok
+}
+
+func (p *printer) safeDecodeCharset(d CharsetDecoder, body []byte) (decoded []byte, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	return d.Decode(body)
+}
@@ -0,0 +1,70 @@
+package httpretty
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecodeCharset(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc      string
+		mediatype string
+		charset   string
+		body      []byte
+		want      string
+	}{
+		{"none", "text/plain", "", []byte("olá"), "olá"},
+		{"utf-8 bom", "text/plain", "", []byte("\xEF\xBB\xBFolá"), "olá"},
+		{"latin-1", "text/plain", "ISO-8859-1", []byte("ol\xe1"), "olá"},
+		{"windows-1252", "text/html", "windows-1252", []byte("\x93quoted\x94 \x80"), "“quoted” €"},
+		{"utf-16le", "application/json", "utf-16le", []byte{'h', 0, 'i', 0}, "hi"},
+		{"utf-16be", "text/plain", "UTF-16BE", []byte{0, 'h', 0, 'i'}, "hi"},
+		{"utf-16 bom", "application/problem+json", "", []byte{0xFF, 0xFE, 'h', 0, 0x3d, 0xd8, 0x00, 0xde}, "h😀"},
+		{"bom overrides charset", "application/xml", "iso-8859-1", []byte{0xFE, 0xFF, 0, 'h'}, "h"},
+		{"odd utf-16", "text/plain", "utf-16le", []byte{'h', 0, 'i'}, "h\x00i"},
+		{"unknown", "text/plain", "shift_jis", []byte("abc"), "abc"},
+		{"binary bom", "application/octet-stream", "", []byte{0xFF, 0xFE, 0x00, 0x01}, "\xFF\xFE\x00\x01"},
+		{"unknown media type bom", "", "", []byte{0xFE, 0xFF, 0, 'h'}, "\xFE\xFF\x00h"},
+		{"binary with charset", "application/octet-stream", "utf-16le", []byte{'h', 0}, "h"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			logger := &Logger{}
+			logger.SetOutput(io.Discard)
+			p := newPrinter(logger)
+			if got := p.decodeCharset(tc.mediatype, tc.charset, tc.body); string(got) != tc.want {
+				t.Errorf("got decoded body %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetCharsetDecoder(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{}
+	logger.SetCharsetDecoder("X-Upper", CharsetDecoderFunc(func(src []byte) ([]byte, error) {
+		return []byte(strings.ToUpper(string(src))), nil
+	}))
+	logger.SetCharsetDecoder("latin1", nil)
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	p := newPrinter(logger)
+	if got := p.decodeCharset("text/plain", "x-upper", []byte("hi")); string(got) != "HI" {
+		t.Errorf("got decoded body %q, wanted HI", got)
+	}
+	if got := p.decodeCharset("text/plain", "latin1", []byte("ol\xe1")); string(got) != "ol\xe1" {
+		t.Errorf("expected latin1 decoder to be removed, got %q", got)
+	}
+	_ = p.decodeCharset("text/plain", "utf-16", []byte{0})
+	p.flush()
+	want := `* body charset latin1 cannot be decoded
+* cannot decode utf-16 body: odd number of bytes for UTF-16
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got output %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVFormatter prints CSV and TSV documents as an aligned table, with the first record as its header.
+//
+// Columns are truncated so the table fits the terminal width, and parse errors are reported
+// with their line number.
+type CSVFormatter struct {
+	// MaxRows to print after the header. Defaults to 50.
+	MaxRows int
+
+	// Width of the terminal, in columns. Defaults to the COLUMNS environment variable, or 80.
+	Width int
+}
+
+// Match CSV and TSV media types.
+func (c *CSVFormatter) Match(mediatype string) bool {
+	switch mediatype {
+	case "text/csv", "application/csv", "text/tab-separated-values", "text/tsv":
+		return true
+	}
+	return false
+}
+
+// csvMinColumnWidth is the width columns aren't truncated below.
+const csvMinColumnWidth = 4
+
+// Format CSV and TSV content.
+func (c *CSVFormatter) Format(w io.Writer, src []byte) error {
+	r := csv.NewReader(bytes.NewReader(src))
+	r.Comma = csvDelimiter(src)
+	maxRows := c.MaxRows
+	if maxRows <= 0 {
+		maxRows = 50
+	}
+	var (
+		records [][]string
+		more    int
+	)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		// the header isn't counted as a row.
+		if len(records) > maxRows {
+			more++
+			continue
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	widths := c.columnWidths(records)
+	var buf bytes.Buffer
+	for i, record := range records {
+		writeCSVRow(&buf, record, widths)
+		if i == 0 {
+			separator := make([]string, len(widths))
+			for j, width := range widths {
+				separator[j] = strings.Repeat("-", width)
+			}
+			writeCSVRow(&buf, separator, widths)
+		}
+	}
+	switch more {
+	case 0:
+	case 1:
+		buf.WriteString("... 1 more row\n")
+	default:
+		fmt.Fprintf(&buf, "... %d more rows\n", more)
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// csvDelimiter guesses if the document is a TSV or CSV file from its first line.
+func csvDelimiter(src []byte) rune {
+	line, _, _ := bytes.Cut(src, []byte("\n"))
+	if bytes.Count(line, []byte("\t")) > bytes.Count(line, []byte(",")) {
+		return '\t'
+	}
+	return ','
+}
+
+func (c *CSVFormatter) width() int {
+	if c.Width > 0 {
+		return c.Width
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
+
+// columnWidths returns the width of each column, shrinking the widest columns until the table fits the terminal.
+func (c *CSVFormatter) columnWidths(records [][]string) []int {
+	var widths []int
+	for _, record := range records {
+		for i, field := range record {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := utf8.RuneCountInString(field); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	total := 2 * (len(widths) - 1) // column separators
+	for _, width := range widths {
+		total += width
+	}
+	for total > c.width() {
+		widest := 0
+		for i, width := range widths {
+			if width > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= csvMinColumnWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+func writeCSVRow(buf *bytes.Buffer, record []string, widths []int) {
+	var line strings.Builder
+	for i, field := range record {
+		if i != 0 {
+			line.WriteString("  ")
+		}
+		field = strings.NewReplacer("\r\n", "↵", "\n", "↵", "\t", " ").Replace(field)
+		n := utf8.RuneCountInString(field)
+		if n > widths[i] {
+			field = string([]rune(field)[:widths[i]-1]) + "…"
+			n = widths[i]
+		}
+		line.WriteString(field)
+		line.WriteString(strings.Repeat(" ", widths[i]-n))
+	}
+	buf.WriteString(strings.TrimRight(line.String(), " "))
+	buf.WriteByte('\n')
+}
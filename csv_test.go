@@ -0,0 +1,85 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		f    *CSVFormatter
+		src  string
+		want string
+	}{
+		{
+			desc: "csv",
+			f:    &CSVFormatter{},
+			src:  "id,name,email\n1,gopher,gopher@example.com\n2,\"Glenda, the bunny\",glenda@example.com\n",
+			want: `id  name               email
+--  -----------------  ------------------
+1   gopher             gopher@example.com
+2   Glenda, the bunny  glenda@example.com`,
+		},
+		{
+			desc: "tsv",
+			f:    &CSVFormatter{},
+			src:  "city\tcountry\nSão Paulo\tBrazil\n",
+			want: `city       country
+---------  -------
+São Paulo  Brazil`,
+		},
+		{
+			desc: "truncated",
+			f:    &CSVFormatter{Width: 24},
+			src:  "id,description\n1,a very long description that doesn't fit\n",
+			want: `id  description
+--  --------------------
+1   a very long descrip…`,
+		},
+		{
+			desc: "max rows",
+			f:    &CSVFormatter{MaxRows: 1},
+			src:  "n\n1\n2\n3\n",
+			want: "n\n-\n1\n... 2 more rows",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := tc.f.Format(&buf, []byte(tc.src)); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCSVFormatterInvalid(t *testing.T) {
+	t.Parallel()
+	f := &CSVFormatter{}
+	if !f.Match("text/csv") || !f.Match("text/tab-separated-values") {
+		t.Error("expected CSVFormatter to match CSV and TSV media types")
+	}
+	testCases := []struct {
+		desc string
+		src  string
+		want string
+	}{
+		{"fields", "a,b\n1,2\n3\n", "record on line 3: wrong number of fields"},
+		{"quote", "a,b\n1,x\"y\n", `parse error on line 2, column 4: bare " in non-quoted-field`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := f.Format(&buf, []byte(tc.src)); err == nil || err.Error() != tc.want {
+				t.Errorf("got format error = %v, wanted %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package httpretty
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/henvic/httpretty/internal/color"
+)
+
+// ContentDecoder decodes bodies with a given Content-Encoding, so they can be printed.
+//
+// Bodies are only decoded for printing: the bytes read by the caller are left untouched.
+type ContentDecoder interface {
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// ContentDecoderFunc is an adapter to allow the use of ordinary functions as a ContentDecoder,
+// such as one returning a brotli or zstd reader.
+type ContentDecoderFunc func(r io.Reader) (io.ReadCloser, error)
+
+// NewReader calls f(r).
+func (f ContentDecoderFunc) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return f(r)
+}
+
+// defaultContentDecoders are used unless replaced or removed with Logger.SetContentDecoder.
+var defaultContentDecoders = map[string]ContentDecoder{
+	"gzip":    ContentDecoderFunc(newGzipReader),
+	"x-gzip":  ContentDecoderFunc(newGzipReader),
+	"deflate": ContentDecoderFunc(newDeflateReader),
+	"zlib":    ContentDecoderFunc(newDeflateReader),
+}
+
+func newGzipReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// newDeflateReader reads deflate streams, which should be zlib-wrapped according to RFC 9110,
+// but are sent raw by some servers.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) >= 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0 {
+		return zlib.NewReader(bytes.NewReader(b))
+	}
+	return flate.NewReader(bytes.NewReader(b)), nil
+}
+
+// errDecodedTooLong is returned when a decoded body is longer than the body limit.
+var errDecodedTooLong = errors.New("decoded body is too long")
+
+// decodeBody decodes a body with the given Content-Encoding for printing.
+// The decoded body is limited to maxLength bytes, or maxDefaultUnknownReadable if it is zero,
+// as a small compressed body might decode into a large one.
+// If the body cannot be decoded, the reason is printed, and ok is false.
+func (p *printer) decodeBody(contentEncoding string, body []byte, maxLength int64) (decoded []byte, ok bool) {
+	var encodings []string
+	for _, e := range strings.Split(contentEncoding, ",") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" && e != "identity" {
+			encodings = append(encodings, e)
+		}
+	}
+	if len(encodings) == 0 {
+		return body, true
+	}
+	if maxLength <= 0 {
+		maxLength = maxDefaultUnknownReadable
+	}
+	decoded = body
+	// encodings are listed in the order they were applied.
+	for i := len(encodings) - 1; i >= 0; i-- {
+		d := p.logger.getContentDecoder(encodings[i])
+		if d == nil {
+			p.printf("* body is encoded with %s, which cannot be decoded\n", encodings[i])
+			return nil, false
+		}
+		var err error
+		decoded, err = p.safeDecode(d, decoded, maxLength)
+		if err == errDecodedTooLong {
+			p.printf("* body is too long, skipping (decoded body is longer than %d bytes)\n", maxLength)
+			return nil, false
+		}
+		if err != nil {
+			p.printf("* cannot decode %s body: %v\n", encodings[i], p.format(color.FgRed, err.Error()))
+			return nil, false
+		}
+	}
+	p.printf("* %s body decoded from %d to %d bytes\n", strings.Join(encodings, ", "), len(body), len(decoded))
+	return decoded, true
+}
+
+func (p *printer) safeDecode(d ContentDecoder, body []byte, maxLength int64) (decoded []byte, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	r, err := d.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	decoded, err = io.ReadAll(io.LimitReader(r, maxLength+1))
+	if err == nil && int64(len(decoded)) > maxLength {
+		err = errDecodedTooLong
+	}
+	return decoded, err
+}
@@ -0,0 +1,118 @@
+package httpretty
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// gzipBytes compresses s. Writing to a bytes.Buffer never fails.
+func gzipBytes(s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestDecodeBody(t *testing.T) {
+	t.Parallel()
+	var zbuf, fbuf bytes.Buffer
+	zw := zlib.NewWriter(&zbuf)
+	_, _ = zw.Write([]byte("hello zlib"))
+	_ = zw.Close()
+	fw, _ := flate.NewWriter(&fbuf, flate.DefaultCompression)
+	_, _ = fw.Write([]byte("hello deflate"))
+	_ = fw.Close()
+	testCases := []struct {
+		desc     string
+		encoding string
+		body     []byte
+		want     string
+		ok       bool
+	}{
+		{"gzip", "gzip", gzipBytes("hello gzip"), "hello gzip", true},
+		{"zlib wrapped deflate", "deflate", zbuf.Bytes(), "hello zlib", true},
+		{"raw deflate", "Deflate", fbuf.Bytes(), "hello deflate", true},
+		{"identity", "identity", []byte("plain"), "plain", true},
+		{"unknown", "br", []byte("???"), "", false},
+		{"invalid", "gzip", []byte("not gzip"), "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			p := newPrinter(&Logger{})
+			p.logger.SetOutput(io.Discard)
+			got, ok := p.decodeBody(tc.encoding, tc.body, 0)
+			if ok != tc.ok || string(got) != tc.want {
+				t.Errorf("got decoded body %q (%v), wanted %q (%v)", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestDecodeBodyTooLong(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	p := newPrinter(logger)
+	if got, ok := p.decodeBody("gzip", gzipBytes(strings.Repeat("a", 11)), 10); ok {
+		t.Errorf("got decoded body %q, wanted it to be skipped", got)
+	}
+	if got, ok := p.decodeBody("gzip", gzipBytes(strings.Repeat("a", 10)), 10); !ok || len(got) != 10 {
+		t.Errorf("got decoded body %q (%v), wanted 10 bytes", got, ok)
+	}
+	if _, ok := p.decodeBody("gzip", gzipBytes(strings.Repeat("a", maxDefaultUnknownReadable+1)), 0); ok {
+		t.Error("expected decoded body longer than the default limit to be skipped")
+	}
+	p.flush()
+	want := `* body is too long, skipping (decoded body is longer than 10 bytes)
+* gzip body decoded from 35 to 10 bytes
+* body is too long, skipping (decoded body is longer than 4096 bytes)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got output %q, wanted %q", got, want)
+	}
+}
+
+func TestSetContentDecoder(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{}
+	logger.SetContentDecoder("BR", ContentDecoderFunc(func(r io.Reader) (io.ReadCloser, error) {
+		b, err := io.ReadAll(r)
+		return io.NopCloser(strings.NewReader(strings.ToUpper(string(b)))), err
+	}))
+	logger.SetContentDecoder("gzip", nil)
+	logger.SetContentDecoder("zstd", ContentDecoderFunc(func(r io.Reader) (io.ReadCloser, error) {
+		return nil, errors.New("bad frame")
+	}))
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	p := newPrinter(logger)
+	if got, ok := p.decodeBody("br", []byte("hi"), 0); !ok || string(got) != "HI" {
+		t.Errorf("got decoded body %q (%v), wanted HI", got, ok)
+	}
+	if _, ok := p.decodeBody("gzip", gzipBytes("hi"), 0); ok {
+		t.Error("expected gzip decoder to be removed")
+	}
+	if _, ok := p.decodeBody("x-gzip, zstd", nil, 0); ok {
+		t.Error("expected zstd decoder to fail")
+	}
+	if logger.getContentDecoder("deflate") == nil {
+		t.Error("expected default deflate decoder to be kept")
+	}
+	p.flush()
+	want := `* br body decoded from 2 to 2 bytes
+* body is encoded with gzip, which cannot be decoded
+* cannot decode zstd body: bad frame
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got output %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,439 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/henvic/httpretty/internal/color"
+)
+
+// GraphQLFormatter formats GraphQL requests and responses.
+//
+// It matches application/graphql and JSON media types. GraphQL requests have their query document
+// pretty-printed separately from their variables, and the errors of GraphQL responses are listed
+// before their data. Other JSON documents are indented like JSONFormatter does.
+//
+// The operation type and name of requests, and the number of errors of responses, are used as their summary.
+type GraphQLFormatter struct{}
+
+// Match GraphQL and JSON media types.
+func (g *GraphQLFormatter) Match(mediatype string) bool {
+	return mediatype == "application/graphql" || jsonTypeRE.MatchString(mediatype)
+}
+
+// Format GraphQL content.
+func (g *GraphQLFormatter) Format(w io.Writer, src []byte) error {
+	return g.format(w, src, false)
+}
+
+// FormatColors formats GraphQL content, highlighting errors in red.
+func (g *GraphQLFormatter) FormatColors(w io.Writer, src []byte) error {
+	return g.format(w, src, true)
+}
+
+// Summarize GraphQL requests with their operation, and responses with their errors.
+func (g *GraphQLFormatter) Summarize(mediatype string, src []byte) string {
+	if mediatype == "application/graphql" {
+		typ, name := graphQLOperation(string(src), "")
+		return "GraphQL " + strings.TrimSpace(typ+" "+name)
+	}
+	if reqs, ok := parseGraphQLRequests(src); ok {
+		var ops []string
+		for _, req := range reqs {
+			typ, name := graphQLOperation(req.Query, req.OperationName)
+			ops = append(ops, strings.TrimSpace(typ+" "+name))
+		}
+		return "GraphQL " + strings.Join(ops, ", ")
+	}
+	if resps, ok := parseGraphQLResponses(src); ok {
+		var errs int
+		for _, resp := range resps {
+			errs += len(resp.Errors)
+		}
+		switch errs {
+		case 0:
+			return ""
+		case 1:
+			return "GraphQL response with 1 error"
+		default:
+			return fmt.Sprintf("GraphQL response with %d errors", errs)
+		}
+	}
+	return ""
+}
+
+func (g *GraphQLFormatter) format(w io.Writer, src []byte, colors bool) error {
+	var buf bytes.Buffer
+	if reqs, ok := parseGraphQLRequests(src); ok {
+		for i, req := range reqs {
+			if len(reqs) > 1 {
+				fmt.Fprintf(&buf, "#%d\n", i)
+			}
+			writeGraphQLRequest(&buf, req)
+		}
+	} else if resps, ok := parseGraphQLResponses(src); ok {
+		for i, resp := range resps {
+			if len(resps) > 1 {
+				fmt.Fprintf(&buf, "#%d\n", i)
+			}
+			writeGraphQLResponse(&buf, resp, colors)
+		}
+	} else if bytes.HasPrefix(bytes.TrimSpace(src), []byte("{")) || bytes.HasPrefix(bytes.TrimSpace(src), []byte("[")) {
+		if err := json.Indent(&buf, src, "", "    "); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+	} else {
+		// application/graphql request body.
+		buf.WriteString(formatGraphQL(string(src)))
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+type graphQLRequest struct {
+	Query         string          `json:"query"`
+	OperationName string          `json:"operationName"`
+	Variables     json.RawMessage `json:"variables"`
+	Extensions    json.RawMessage `json:"extensions"`
+}
+
+type graphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []graphQLLocation `json:"locations"`
+	Path       []interface{}     `json:"path"`
+	Extensions json.RawMessage   `json:"extensions"`
+}
+
+type graphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type graphQLResponse struct {
+	Data       json.RawMessage `json:"data"`
+	Errors     []graphQLError  `json:"errors"`
+	Extensions json.RawMessage `json:"extensions"`
+}
+
+// parseGraphQL decodes a JSON object, or a batch of them, whose keys are all allowed.
+func parseGraphQL(src []byte, allowed ...string) ([]json.RawMessage, bool) {
+	src = bytes.TrimSpace(src)
+	var objects []json.RawMessage
+	if bytes.HasPrefix(src, []byte("[")) {
+		if err := json.Unmarshal(src, &objects); err != nil || len(objects) == 0 {
+			return nil, false
+		}
+	} else {
+		objects = []json.RawMessage{src}
+	}
+	for _, o := range objects {
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(o, &m); err != nil || len(m) == 0 {
+			return nil, false
+		}
+		for k := range m {
+			found := false
+			for _, a := range allowed {
+				if k == a {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		}
+	}
+	return objects, true
+}
+
+func parseGraphQLRequests(src []byte) ([]graphQLRequest, bool) {
+	objects, ok := parseGraphQL(src, "query", "operationName", "variables", "extensions")
+	if !ok {
+		return nil, false
+	}
+	reqs := make([]graphQLRequest, len(objects))
+	for i, o := range objects {
+		if err := json.Unmarshal(o, &reqs[i]); err != nil {
+			return nil, false
+		}
+		// persisted queries might be sent without a query document.
+		if reqs[i].Query == "" && !bytes.Contains(reqs[i].Extensions, []byte(`"persistedQuery"`)) {
+			return nil, false
+		}
+	}
+	return reqs, true
+}
+
+func parseGraphQLResponses(src []byte) ([]graphQLResponse, bool) {
+	objects, ok := parseGraphQL(src, "data", "errors", "extensions")
+	if !ok {
+		return nil, false
+	}
+	resps := make([]graphQLResponse, len(objects))
+	for i, o := range objects {
+		if err := json.Unmarshal(o, &resps[i]); err != nil {
+			return nil, false
+		}
+		if resps[i].Data == nil && resps[i].Errors == nil {
+			return nil, false
+		}
+	}
+	return resps, true
+}
+
+func writeGraphQLRequest(buf *bytes.Buffer, req graphQLRequest) {
+	if req.OperationName != "" {
+		fmt.Fprintf(buf, "operationName: %s\n", req.OperationName)
+	}
+	if req.Query != "" {
+		buf.WriteString(formatGraphQL(req.Query))
+		buf.WriteByte('\n')
+	}
+	writeGraphQLJSON(buf, "variables", req.Variables)
+	writeGraphQLJSON(buf, "extensions", req.Extensions)
+}
+
+func writeGraphQLResponse(buf *bytes.Buffer, resp graphQLResponse, colors bool) {
+	if len(resp.Errors) != 0 {
+		buf.WriteString("errors:\n")
+	}
+	for _, e := range resp.Errors {
+		lines := []string{"- " + e.Message}
+		if len(e.Path) != 0 {
+			var path []string
+			for _, p := range e.Path {
+				path = append(path, fmt.Sprint(p))
+			}
+			lines = append(lines, "  path: "+strings.Join(path, "."))
+		}
+		if len(e.Locations) != 0 {
+			var locations []string
+			for _, l := range e.Locations {
+				locations = append(locations, fmt.Sprintf("%d:%d", l.Line, l.Column))
+			}
+			lines = append(lines, "  locations: "+strings.Join(locations, ", "))
+		}
+		var extensions bytes.Buffer
+		if err := json.Compact(&extensions, e.Extensions); err == nil && extensions.String() != "null" {
+			lines = append(lines, "  extensions: "+extensions.String())
+		}
+		for _, line := range lines {
+			if colors {
+				// escaped, so that the message cannot set colors of its own.
+				line = color.Format(color.FgRed, color.Escape(line))
+			}
+			buf.WriteString(line + "\n")
+		}
+	}
+	writeGraphQLJSON(buf, "data", resp.Data)
+	writeGraphQLJSON(buf, "extensions", resp.Extensions)
+}
+
+func writeGraphQLJSON(buf *bytes.Buffer, name string, v json.RawMessage) {
+	if len(v) == 0 {
+		return
+	}
+	buf.WriteString(name + ":\n")
+	if err := json.Indent(buf, v, "", "    "); err != nil {
+		buf.Write(v)
+	}
+	buf.WriteByte('\n')
+}
+
+// graphQLOperation returns the type and name of the operation of a GraphQL document.
+// If name is empty, the first operation is used.
+func graphQLOperation(doc, name string) (typ, opName string) {
+	var depth int
+	tokens := graphQLTokens(doc)
+	for i, t := range tokens {
+		switch t {
+		case "{", "(", "[":
+			if depth == 0 && t == "{" && name == "" && (i == 0 || tokens[i-1] == "}") {
+				return "query", "" // query shorthand
+			}
+			depth++
+		case "}", ")", "]":
+			depth--
+		case "query", "mutation", "subscription":
+			if depth != 0 {
+				continue
+			}
+			var n string
+			if i+1 < len(tokens) && isGraphQLName(tokens[i+1]) {
+				n = tokens[i+1]
+			}
+			if name == "" || name == n {
+				return t, n
+			}
+		}
+	}
+	if name != "" {
+		return "operation", name
+	}
+	return "", ""
+}
+
+func isGraphQLName(t string) bool {
+	if t == "" {
+		return false
+	}
+	for i, r := range t {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !(i > 0 && '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// graphQLTokens splits a GraphQL document into tokens, ignoring commas.
+// Comments are kept as tokens starting with #.
+func graphQLTokens(doc string) []string {
+	var tokens []string
+	for i := 0; i < len(doc); {
+		c := doc[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			end := strings.IndexAny(doc[i:], "\r\n")
+			if end == -1 {
+				end = len(doc) - i
+			}
+			tokens = append(tokens, doc[i:i+end])
+			i += end
+		case strings.HasPrefix(doc[i:], `"""`):
+			end := i + 3
+			for end < len(doc) && !strings.HasPrefix(doc[end:], `"""`) {
+				if strings.HasPrefix(doc[end:], `\"""`) {
+					end += 4
+					continue
+				}
+				end++
+			}
+			end = min(end+3, len(doc))
+			tokens = append(tokens, doc[i:end])
+			i = end
+		case c == '"':
+			end := i + 1
+			for end < len(doc) && doc[end] != '"' && doc[end] != '\n' {
+				if doc[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(doc))
+			tokens = append(tokens, doc[i:end])
+			i = end
+		case strings.HasPrefix(doc[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.ContainsRune("!$&()/:=@[]{}|", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			end := i + 1
+			for end < len(doc) && !strings.ContainsRune(" \t\n\r,#\"!$&()/:=@[]{}|.", rune(doc[end])) {
+				end++
+			}
+			// numbers might have a decimal point.
+			for end < len(doc) && doc[end] == '.' && !strings.HasPrefix(doc[end:], "...") {
+				end++
+				for end < len(doc) && !strings.ContainsRune(" \t\n\r,#\"!$&()/:=@[]{}|.", rune(doc[end])) {
+					end++
+				}
+			}
+			tokens = append(tokens, doc[i:end])
+			i = end
+		}
+	}
+	return tokens
+}
+
+// formatGraphQL pretty-prints a GraphQL document, indenting its selection sets.
+func formatGraphQL(doc string) string {
+	var (
+		b      strings.Builder
+		indent int
+		nested int // depth of arguments, lists, and input objects, which are printed inline
+		prev   string
+		spread bool // after a fragment spread or inline fragment
+		closed bool // after the end of a selection set
+	)
+	newline := func() {
+		if b.Len() != 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(strings.Repeat("    ", indent))
+		prev = ""
+	}
+	for _, t := range graphQLTokens(doc) {
+		switch {
+		case strings.HasPrefix(t, "#"):
+			if prev != "" {
+				newline()
+			}
+			b.WriteString(t)
+			newline()
+			continue
+		case t == "{" && nested == 0:
+			if prev != "" {
+				b.WriteByte(' ')
+			}
+			b.WriteString("{")
+			indent++
+			newline()
+			continue
+		case t == "}" && nested == 0:
+			indent = max(indent-1, 0)
+			if prev != "" {
+				newline()
+			} else {
+				// remove the indentation of the empty line.
+				s := strings.TrimRight(b.String(), " ")
+				b.Reset()
+				b.WriteString(s)
+				b.WriteString(strings.Repeat("    ", indent))
+			}
+			b.WriteString("}")
+			prev, closed = "}", true
+			if indent == 0 {
+				b.WriteString("\n\n")
+				prev, closed = "", false
+			}
+			continue
+		case t == "(" || t == "[" || (t == "{" && nested != 0):
+			nested++
+		case t == ")" || t == "]" || t == "}":
+			nested = max(nested-1, 0)
+		}
+		startsField := indent > 0 && nested == 0 && prev != "" && (t == "..." || isGraphQLName(t) &&
+			prev != ":" && prev != "@" && prev != "..." && prev != "=" && !(spread && prev == "on"))
+		if closed || startsField {
+			newline()
+		}
+		closed = false
+		switch {
+		case prev == "...":
+			if t == "on" {
+				b.WriteByte(' ')
+			}
+		case prev == "", prev == "(", prev == "[", prev == "$", prev == "@",
+			t == "(", t == ")", t == "]", t == ":", t == "!",
+			nested != 0 && (prev == "{" || t == "}"):
+		case nested != 0 && prev != ":" && prev != "=" && t != "}" && t != "=" && t != "|":
+			b.WriteString(", ")
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(t)
+		spread = t == "..." || (spread && t == "on")
+		prev = t
+	}
+	return strings.TrimRight(b.String(), "\n ")
+}
@@ -0,0 +1,119 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGraphQLFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc      string
+		mediatype string
+		src       string
+		colors    bool
+		want      string
+		summary   string
+	}{
+		{
+			desc:      "request",
+			mediatype: "application/json",
+			src:       `{"query":"query GetUser($id: ID!) { user(id: $id) { id name friends(first: 10) { ...F } } } fragment F on User { id }","operationName":"GetUser","variables":{"id":"1"}}`,
+			want: `operationName: GetUser
+query GetUser($id: ID!) {
+    user(id: $id) {
+        id
+        name
+        friends(first: 10) {
+            ...F
+        }
+    }
+}
+
+fragment F on User {
+    id
+}
+variables:
+{
+    "id": "1"
+}`,
+			summary: "GraphQL query GetUser",
+		},
+		{
+			desc:      "batch",
+			mediatype: "application/json",
+			src:       `[{"query":"{ me { id } }"},{"query":"mutation Like { like(id: 1) }"}]`,
+			want: `#0
+{
+    me {
+        id
+    }
+}
+#1
+mutation Like {
+    like(id: 1)
+}`,
+			summary: "GraphQL query, mutation Like",
+		},
+		{
+			desc:      "graphql",
+			mediatype: "application/graphql",
+			src:       `subscription OnEvent { event { id } }`,
+			want: `subscription OnEvent {
+    event {
+        id
+    }
+}`,
+			summary: "GraphQL subscription OnEvent",
+		},
+		{
+			desc:      "response",
+			mediatype: "application/graphql-response+json",
+			src:       `{"errors":[{"message":"Not found","path":["user",0,"name"],"locations":[{"line":1,"column":3}],"extensions":{"code":"NOT_FOUND"}}],"data":{"user":null}}`,
+			colors:    true,
+			want: "errors:\n" +
+				"\x1b[31m- Not found\x1b[0m\n" +
+				"\x1b[31m  path: user.0.name\x1b[0m\n" +
+				"\x1b[31m  locations: 1:3\x1b[0m\n" +
+				"\x1b[31m  extensions: {\"code\":\"NOT_FOUND\"}\x1b[0m\n" +
+				"data:\n{\n    \"user\": null\n}",
+			summary: "GraphQL response with 1 error",
+		},
+		{
+			desc:      "response with escape sequences",
+			mediatype: "application/json",
+			src:       `{"errors":[{"message":"\u001b]0;pwn\u0007\u001b[32mok"}]}`,
+			colors:    true,
+			want:      "errors:\n\x1b[31m- \\x1b]0;pwn\\a\\x1b[32mok\x1b[0m",
+			summary:   "GraphQL response with 1 error",
+		},
+		{
+			desc:      "JSON",
+			mediatype: "application/json",
+			src:       `{"query":1,"other":true}`,
+			want:      "{\n    \"query\": 1,\n    \"other\": true\n}",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := &GraphQLFormatter{}
+			if !f.Match(tc.mediatype) {
+				t.Errorf("expected GraphQLFormatter to match %q", tc.mediatype)
+			}
+			var buf bytes.Buffer
+			format := f.Format
+			if tc.colors {
+				format = f.FormatColors
+			}
+			if err := format(&buf, []byte(tc.src)); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %q, wanted %q", got, tc.want)
+			}
+			if got := f.Summarize(tc.mediatype, []byte(tc.src)); got != tc.summary {
+				t.Errorf("got summary %q, wanted %q", got, tc.summary)
+			}
+		})
+	}
+}
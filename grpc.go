@@ -0,0 +1,397 @@
+package httpretty
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GRPCFormatter formats gRPC, gRPC-Web, and Connect streaming bodies.
+//
+// Bodies are split into their length-prefixed messages, which are printed separately with their index,
+// and formatted with the first of Formatters matching application/x-protobuf or application/json,
+// depending on the codec. Protocol buffers messages are decoded with a ProtobufFormatter by default.
+//
+// The gRPC status of responses is decoded from gRPC-Web trailer frames, Connect end-of-stream messages,
+// and the response trailers, and is printed as the response summary.
+type GRPCFormatter struct {
+	// Formatters used to format messages, such as a ProtobufFormatter with Descriptors.
+	Formatters []Formatter
+}
+
+// Match gRPC, gRPC-Web, and Connect streaming media types.
+func (g *GRPCFormatter) Match(mediatype string) bool {
+	return mediatype == "application/grpc" || strings.HasPrefix(mediatype, "application/grpc+") ||
+		mediatype == "application/grpc-web" || strings.HasPrefix(mediatype, "application/grpc-web+") ||
+		mediatype == "application/grpc-web-text" || strings.HasPrefix(mediatype, "application/grpc-web-text+") ||
+		strings.HasPrefix(mediatype, "application/connect+")
+}
+
+// AcceptsBinary data.
+func (g *GRPCFormatter) AcceptsBinary() bool {
+	return true
+}
+
+// Format gRPC messages.
+func (g *GRPCFormatter) Format(w io.Writer, src []byte) error {
+	var buf bytes.Buffer
+	s := g.NewStream(&buf)
+	if _, err := s.Write(src); err != nil {
+		return err
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+	// the body is printed with a trailing newline already.
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// Summarize gRPC responses with their status, if a trailer frame or end-of-stream message is found.
+func (g *GRPCFormatter) Summarize(mediatype string, src []byte) string {
+	if isGRPCWebText(src) {
+		src = decodeGRPCWebText(src)
+	}
+	for len(src) >= 5 {
+		flags, n := src[0], binary.BigEndian.Uint32(src[1:5])
+		if uint64(n) > uint64(len(src)-5) {
+			break
+		}
+		payload := src[5 : 5+n]
+		src = src[5+n:]
+		switch {
+		case flags&grpcWebTrailerFlag != 0:
+			trailer, err := parseGRPCWebTrailer(payload)
+			if err == nil {
+				return grpcStatusSummary(trailer)
+			}
+		case flags&connectEndStreamFlag != 0 && strings.HasPrefix(mediatype, "application/connect+"):
+			return connectEndStreamSummary(payload)
+		}
+	}
+	return ""
+}
+
+// summarizeHeader with the gRPC status of responses, which trailers-only responses carry in the header.
+func (g *GRPCFormatter) summarizeHeader(h, trailer http.Header) string {
+	return grpcStatusSummary(trailer, h)
+}
+
+// NewStream returns a writer that formats each message as soon as it is complete.
+func (g *GRPCFormatter) NewStream(w io.Writer) io.WriteCloser {
+	return &grpcStream{
+		f: g,
+		w: w,
+	}
+}
+
+// newLimitedStream returns a stream skipping frames longer than max bytes, rather than buffering them.
+func (g *GRPCFormatter) newLimitedStream(w io.Writer, max int64) io.WriteCloser {
+	return &grpcStream{
+		f:   g,
+		w:   w,
+		max: max,
+	}
+}
+
+const (
+	grpcCompressedFlag   = 0x01
+	connectEndStreamFlag = 0x02
+	grpcWebTrailerFlag   = 0x80
+)
+
+type grpcStream struct {
+	f       *GRPCFormatter
+	w       io.Writer
+	buf     []byte
+	text    []byte // undecoded grpc-web-text
+	started bool
+	isText  bool
+	index   int
+	max     int64 // maximum length of a frame, if set
+	skip    int64 // bytes of a frame too long to print left to skip
+}
+
+func (s *grpcStream) Write(p []byte) (int, error) {
+	if !s.started && len(p) != 0 {
+		s.started = true
+		s.isText = isGRPCWebText(p)
+	}
+	if s.isText {
+		s.text = append(s.text, p...)
+		// decode complete base64 quanta.
+		n := len(s.text) - len(s.text)%4
+		s.buf = append(s.buf, decodeGRPCWebText(s.text[:n])...)
+		s.text = s.text[n:]
+	} else {
+		s.buf = append(s.buf, p...)
+	}
+	for {
+		if s.skip > 0 {
+			skipped := min(s.skip, int64(len(s.buf)))
+			s.buf = s.buf[skipped:]
+			s.skip -= skipped
+		}
+		if len(s.buf) < 5 {
+			break
+		}
+		n := binary.BigEndian.Uint32(s.buf[1:5])
+		// the length prefix isn't trusted to buffer frames.
+		if s.max > 0 && int64(n) > s.max {
+			if err := s.skipFrame(s.buf[0], n); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if uint64(n) > uint64(len(s.buf)-5) {
+			break
+		}
+		if err := s.writeFrame(s.buf[0], s.buf[5:5+n]); err != nil {
+			return 0, err
+		}
+		s.buf = s.buf[5+n:]
+	}
+	return len(p), nil
+}
+
+// skipFrame reports a frame too long to print, and skips it.
+func (s *grpcStream) skipFrame(flags byte, n uint32) error {
+	s.skip = 5 + int64(n)
+	name := "frame"
+	if flags&(grpcWebTrailerFlag|connectEndStreamFlag) == 0 {
+		name = fmt.Sprintf("message #%d", s.index)
+		s.index++
+	}
+	_, err := fmt.Fprintf(s.w, "%s is too long (%d bytes), skipping (longer than %d bytes)\n", name, n, s.max)
+	return err
+}
+
+// Close reports any incomplete frame left.
+func (s *grpcStream) Close() error {
+	if len(s.buf) == 0 && len(s.text) == 0 || s.skip > 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(s.w, "incomplete frame (%d bytes)\n", len(s.buf)+len(s.text))
+	s.buf, s.text = nil, nil
+	return err
+}
+
+func (s *grpcStream) writeFrame(flags byte, payload []byte) error {
+	var buf bytes.Buffer
+	switch {
+	case flags&grpcWebTrailerFlag != 0:
+		buf.WriteString("trailers\n")
+		trailer, err := parseGRPCWebTrailer(payload)
+		if err != nil {
+			fmt.Fprintf(&buf, "%s\n", strconv.Quote(string(payload)))
+			break
+		}
+		writeGRPCTrailer(&buf, trailer)
+	case flags&connectEndStreamFlag != 0:
+		buf.WriteString("end of stream\n")
+		if err := json.Indent(&buf, payload, "", "    "); err != nil {
+			buf.Write(payload)
+		}
+		buf.WriteByte('\n')
+	default:
+		compressed := flags&grpcCompressedFlag != 0
+		fmt.Fprintf(&buf, "message #%d (", s.index)
+		if compressed {
+			buf.WriteString("compressed, ")
+		}
+		fmt.Fprintf(&buf, "%d bytes)\n", len(payload))
+		s.index++
+		if compressed {
+			// the message encoding isn't known here, but gzip is easy to recognize.
+			zr, err := gzip.NewReader(bytes.NewReader(payload))
+			if err != nil {
+				break
+			}
+			if payload, err = io.ReadAll(zr); err != nil {
+				buf.WriteString("cannot decompress message\n")
+				break
+			}
+		}
+		buf.WriteString(s.f.formatMessage(payload))
+		buf.WriteByte('\n')
+	}
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+func (g *GRPCFormatter) formatMessage(payload []byte) string {
+	mediatype := "application/x-protobuf"
+	if json.Valid(payload) {
+		mediatype = "application/json"
+	}
+	formatters := g.Formatters
+	if formatters == nil {
+		formatters = []Formatter{&ProtobufFormatter{}, &JSONFormatter{}}
+	}
+	for _, f := range formatters {
+		if !f.Match(mediatype) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := f.Format(&buf, payload); err != nil {
+			break
+		}
+		return buf.String()
+	}
+	return strconv.Quote(string(payload))
+}
+
+// isGRPCWebText checks if the body is base64 encoded, as frames start with a flags byte.
+func isGRPCWebText(src []byte) bool {
+	if len(src) == 0 {
+		return false
+	}
+	c := src[0]
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '+' || c == '/'
+}
+
+// decodeGRPCWebText decodes base64 chunks, which might be padded individually.
+func decodeGRPCWebText(src []byte) []byte {
+	var dst []byte
+	for len(src) != 0 {
+		end := bytes.IndexByte(src, '=')
+		if end == -1 {
+			end = len(src)
+		} else {
+			for end < len(src) && src[end] == '=' {
+				end++
+			}
+		}
+		b, err := base64.StdEncoding.DecodeString(string(src[:end]))
+		if err != nil {
+			return append(dst, src...)
+		}
+		dst = append(dst, b...)
+		src = src[end:]
+	}
+	return dst
+}
+
+func parseGRPCWebTrailer(payload []byte) (http.Header, error) {
+	r := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(payload), strings.NewReader("\r\n\r\n"))))
+	h, err := r.ReadMIMEHeader()
+	return http.Header(h), err
+}
+
+func writeGRPCTrailer(buf *bytes.Buffer, trailer http.Header) {
+	for _, key := range sortedKeys(trailer) {
+		for _, v := range trailer[key] {
+			if key == "Grpc-Status" {
+				if code, err := strconv.Atoi(v); err == nil {
+					v = fmt.Sprintf("%d (%s)", code, grpcCodeName(code))
+				}
+			}
+			fmt.Fprintf(buf, "%s: %s\n", strings.ToLower(key), v)
+		}
+	}
+}
+
+func sortedKeys(h http.Header) []string {
+	_, keys := sortHeaderKeys(h, nil)
+	return keys
+}
+
+var grpcCodes = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+func grpcCodeName(code int) string {
+	if code >= 0 && code < len(grpcCodes) {
+		return grpcCodes[code]
+	}
+	return fmt.Sprintf("CODE(%d)", code)
+}
+
+// grpcStatusSummary returns the gRPC status from the first of the headers containing it.
+func grpcStatusSummary(headers ...http.Header) string {
+	for _, h := range headers {
+		status := h.Get("Grpc-Status")
+		if status == "" {
+			continue
+		}
+		code, err := strconv.Atoi(status)
+		if err != nil {
+			return "gRPC status " + status
+		}
+		summary := "gRPC status " + grpcCodeName(code)
+		if msg := h.Get("Grpc-Message"); msg != "" {
+			if unescaped, err := url.PathUnescape(msg); err == nil {
+				msg = unescaped
+			}
+			summary += ": " + msg
+		}
+		return summary
+	}
+	return ""
+}
+
+func connectEndStreamSummary(payload []byte) string {
+	var end struct {
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &end); err != nil {
+		return ""
+	}
+	if end.Error == nil {
+		return "Connect end of stream"
+	}
+	summary := "Connect error " + end.Error.Code
+	if end.Error.Message != "" {
+		summary += ": " + end.Error.Message
+	}
+	return summary
+}
+
+// serverTrailers returns the trailers a handler set, either declared with the Trailer header,
+// or using the http.TrailerPrefix.
+func serverTrailers(h http.Header) http.Header {
+	trailer := http.Header{}
+	for _, declared := range h.Values("Trailer") {
+		for _, key := range strings.Split(declared, ",") {
+			key = http.CanonicalHeaderKey(strings.TrimSpace(key))
+			if v, ok := h[key]; ok {
+				trailer[key] = v
+			}
+		}
+	}
+	for key, v := range h {
+		if strings.HasPrefix(key, http.TrailerPrefix) {
+			trailer[http.CanonicalHeaderKey(strings.TrimPrefix(key, http.TrailerPrefix))] = v
+		}
+	}
+	return trailer
+}
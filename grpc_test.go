@@ -0,0 +1,176 @@
+package httpretty
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// grpcFrame encodes a length-prefixed message.
+func grpcFrame(flags byte, payload []byte) []byte {
+	b := binary.BigEndian.AppendUint32([]byte{flags}, uint32(len(payload)))
+	return append(b, payload...)
+}
+
+func TestGRPCFormatter(t *testing.T) {
+	t.Parallel()
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, _ = zw.Write(protoEncoder{}.string(1, "zipped"))
+	_ = zw.Close()
+	var src []byte
+	src = append(src, grpcFrame(0, protoEncoder{}.varint(1, 42))...)
+	src = append(src, grpcFrame(grpcCompressedFlag, compressed.Bytes())...)
+	src = append(src, grpcFrame(grpcWebTrailerFlag, []byte("grpc-status: 5\r\ngrpc-message: user%20not%20found\r\n"))...)
+	f := &GRPCFormatter{}
+	for _, mediatype := range []string{"application/grpc", "application/grpc+proto", "application/grpc-web+proto", "application/grpc-web-text", "application/connect+json"} {
+		if !f.Match(mediatype) {
+			t.Errorf("expected GRPCFormatter to match %s", mediatype)
+		}
+	}
+	if f.Match("application/json") {
+		t.Error("expected GRPCFormatter to not match application/json")
+	}
+	want := `message #0 (2 bytes)
+1: 42
+message #1 (compressed, ` + strconv.Itoa(compressed.Len()) + ` bytes)
+1: "zipped"
+trailers
+grpc-message: user%20not%20found
+grpc-status: 5 (NOT_FOUND)`
+	for _, body := range [][]byte{src, []byte(base64.StdEncoding.EncodeToString(src))} {
+		var buf bytes.Buffer
+		if err := f.Format(&buf, body); err != nil {
+			t.Errorf("got format error = %v, wanted nil", err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("got formatted body %s, wanted %s", got, want)
+		}
+		if got, want := f.Summarize("application/grpc-web", body), "gRPC status NOT_FOUND: user not found"; got != want {
+			t.Errorf("got summary %q, wanted %q", got, want)
+		}
+	}
+}
+
+func TestGRPCFormatterStream(t *testing.T) {
+	t.Parallel()
+	src := append(grpcFrame(0, protoEncoder{}.varint(1, 1)), grpcFrame(0, protoEncoder{}.varint(1, 2))...)
+	var buf bytes.Buffer
+	s := (&GRPCFormatter{}).NewStream(&buf)
+	for i := range src {
+		if _, err := s.Write(src[i : i+1]); err != nil {
+			t.Fatalf("got write error = %v, wanted nil", err)
+		}
+		if i == 6 {
+			if got, want := buf.String(), "message #0 (2 bytes)\n1: 1\n"; got != want {
+				t.Errorf("got streamed message %q, wanted %q", got, want)
+			}
+		}
+	}
+	_, _ = s.Write(grpcFrame(0, []byte("partial"))[:6])
+	if err := s.Close(); err != nil {
+		t.Errorf("got close error = %v, wanted nil", err)
+	}
+	want := "message #0 (2 bytes)\n1: 1\nmessage #1 (2 bytes)\n1: 2\nincomplete frame (6 bytes)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted stream %q, wanted %q", got, want)
+	}
+}
+
+func TestGRPCFormatterLimitedStream(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	s := (&GRPCFormatter{}).newLimitedStream(&buf, 4)
+	long := grpcFrame(0, protoEncoder{}.string(1, "gopher"))
+	chunks := [][]byte{
+		grpcFrame(0, protoEncoder{}.varint(1, 1)),
+		long[:3], long[3:7], long[7:],
+		grpcFrame(0, protoEncoder{}.varint(1, 2)),
+		{0, 0xff, 0xff, 0xff, 0xff, 1, 2, 3}, // a length prefix that cannot be trusted
+	}
+	for _, chunk := range chunks {
+		if _, err := s.Write(chunk); err != nil {
+			t.Fatalf("got write error = %v, wanted nil", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("got close error = %v, wanted nil", err)
+	}
+	want := "message #0 (2 bytes)\n1: 1\n" +
+		"message #1 is too long (8 bytes), skipping (longer than 4 bytes)\n" +
+		"message #2 (2 bytes)\n1: 2\n" +
+		"message #3 is too long (4294967295 bytes), skipping (longer than 4 bytes)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted stream %q, wanted %q", got, want)
+	}
+}
+
+func TestGRPCFormatterConnect(t *testing.T) {
+	t.Parallel()
+	var src []byte
+	src = append(src, grpcFrame(0, []byte(`{"name":"gopher"}`))...)
+	src = append(src, grpcFrame(connectEndStreamFlag, []byte(`{"error":{"code":"not_found","message":"no such user"}}`))...)
+	f := &GRPCFormatter{}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, src); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `message #0 (17 bytes)
+{
+    "name": "gopher"
+}
+end of stream
+{
+    "error": {
+        "code": "not_found",
+        "message": "no such user"
+    }
+}`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+	if got, want := f.Summarize("application/connect+json", src), "Connect error not_found: no such user"; got != want {
+		t.Errorf("got summary %q, wanted %q", got, want)
+	}
+}
+
+func TestGRPCStatusSummary(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc    string
+		headers []http.Header
+		want    string
+	}{
+		{"none", []http.Header{{}, {}}, ""},
+		{"ok", []http.Header{{"Grpc-Status": {"0"}}}, "gRPC status OK"},
+		{"trailer first", []http.Header{{"Grpc-Status": {"16"}}, {"Grpc-Status": {"0"}}}, "gRPC status UNAUTHENTICATED"},
+		{"unknown code", []http.Header{{"Grpc-Status": {"99"}}}, "gRPC status CODE(99)"},
+		{"message", []http.Header{{"Grpc-Status": {"3"}, "Grpc-Message": {"bad%20id"}}}, "gRPC status INVALID_ARGUMENT: bad id"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			if got := grpcStatusSummary(tc.headers...); got != tc.want {
+				t.Errorf("got summary %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestServerTrailers(t *testing.T) {
+	t.Parallel()
+	h := http.Header{
+		"Trailer":              {"Grpc-Status"},
+		"Grpc-Status":          {"0"},
+		"Trailer:Grpc-Message": {"done"},
+		"Content-Type":         {"application/grpc"},
+	}
+	trailer := serverTrailers(h)
+	if len(trailer) != 2 || trailer.Get("Grpc-Status") != "0" || trailer.Get("Grpc-Message") != "done" {
+		t.Errorf("got trailers %v, wanted Grpc-Status and Grpc-Message", trailer)
+	}
+}
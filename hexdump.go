@@ -0,0 +1,83 @@
+package httpretty
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// printHexDump prints binary data as a hexdump, limited to the first Logger.HexDump bytes,
+// along with its detected media type, and the size and SHA-256 checksum of the captured bytes,
+// before they were decoded.
+func (p *printer) printHexDump(body, captured []byte) {
+	sum := sha256.Sum256(captured)
+	p.printf("* body contains binary data: %s, %d bytes, sha256 %s\n",
+		http.DetectContentType(body), len(captured), hex.EncodeToString(sum[:]))
+	dump := body
+	if len(dump) > p.logger.HexDump {
+		dump = dump[:p.logger.HexDump]
+	}
+	if p.logger.RedactPII != 0 {
+		// the text column isn't printed, as it might show personally identifiable information.
+		p.print(stripHexDumpText(hex.Dump(dump)))
+	} else {
+		p.print(hex.Dump(dump))
+	}
+	if more := len(body) - len(dump); more > 0 {
+		p.printf("* %d more bytes\n", more)
+	}
+}
+
+// hexDumpLimit returns how much of a body with a binary media type is read for a hexdump:
+// Logger.HexDump bytes, or maxLength if it is set and smaller.
+func (p *printer) hexDumpLimit(maxLength int64) int64 {
+	if limit := int64(p.logger.HexDump); maxLength <= 0 || limit < maxLength {
+		return limit
+	}
+	return maxLength
+}
+
+// printHexDumpHead prints a hexdump of the first bytes of a body with a binary media type,
+// without reading the rest of it, and returns a body reading the bytes read followed by the rest.
+func (p *printer) printHexDumpHead(r io.ReadCloser, contentLength, maxLength int64) (newBody io.ReadCloser) {
+	limit := p.hexDumpLimit(maxLength)
+	head := make([]byte, limit+1) // read one extra byte to know if the body is longer
+	n, err := io.ReadFull(r, head)
+	head = head[:n]
+	newBody = newBodyReaderBuf(bytes.NewReader(head), r)
+	switch {
+	case err == io.EOF && n == 0:
+	case err == nil:
+		p.printBinaryHead(head[:limit], contentLength)
+	case err == io.ErrUnexpectedEOF:
+		p.printBinaryHead(head, int64(n))
+	default:
+		p.printf("* cannot read body: %v (%d bytes read)\n", err, n)
+	}
+	return newBody
+}
+
+// printBinaryHead prints a hexdump of the captured head of a body of the given size, which is -1 if unknown.
+func (p *printer) printBinaryHead(head []byte, size int64) {
+	p.printHexDump(head, head)
+	switch {
+	case size < 0:
+		p.println("* more bytes follow")
+	case size > int64(len(head)):
+		p.printf("* %d more bytes\n", size-int64(len(head)))
+	}
+}
+
+// stripHexDumpText removes the column of printable characters of each line of a hexdump.
+func stripHexDumpText(dump string) string {
+	lines := strings.SplitAfter(dump, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "  |"); j != -1 {
+			lines[i] = strings.TrimRight(line[:j], " ") + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,342 @@
+package httpretty
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"strings"
+)
+
+// HTMLMode defines how HTMLFormatter prints documents.
+type HTMLMode int
+
+const (
+	// HTMLIndent pretty-prints the markup, indenting nested elements.
+	HTMLIndent HTMLMode = iota
+
+	// HTMLText extracts the readable text of the document: its title, headings, and visible text,
+	// without scripts and styles.
+	HTMLText
+)
+
+// HTMLFormatter formats HTML documents, such as error pages returned by proxies.
+//
+// It uses a lenient tokenizer rather than a full HTML parser, so malformed markup is printed as best as possible.
+type HTMLFormatter struct {
+	Mode HTMLMode
+}
+
+// Match HTML media types.
+func (h *HTMLFormatter) Match(mediatype string) bool {
+	return mediatype == "text/html" || mediatype == "application/xhtml+xml"
+}
+
+// Format HTML content.
+func (h *HTMLFormatter) Format(w io.Writer, src []byte) error {
+	tokens := tokenizeHTML(string(src))
+	var buf bytes.Buffer
+	if h.Mode == HTMLText {
+		writeHTMLText(&buf, tokens)
+	} else {
+		writeHTMLIndent(&buf, tokens)
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+type htmlTokenKind int
+
+const (
+	htmlText htmlTokenKind = iota
+	htmlStartTag
+	htmlEndTag
+	htmlComment
+	htmlDoctype
+)
+
+type htmlToken struct {
+	kind        htmlTokenKind
+	name        string // lowercase tag name
+	raw         string
+	selfClosing bool
+}
+
+// htmlRawText elements have their content kept as text until their end tag.
+var htmlRawText = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+}
+
+var htmlVoid = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// tokenizeHTML splits a document into tags and text. Anything it cannot make sense of is kept as text.
+func tokenizeHTML(s string) []htmlToken {
+	var tokens []htmlToken
+	text := func(t string) {
+		if t != "" {
+			tokens = append(tokens, htmlToken{kind: htmlText, raw: t})
+		}
+	}
+	for len(s) != 0 {
+		i := strings.IndexByte(s, '<')
+		if i == -1 {
+			text(s)
+			break
+		}
+		text(s[:i])
+		s = s[i:]
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end == -1 {
+				tokens = append(tokens, htmlToken{kind: htmlComment, raw: s})
+				return tokens
+			}
+			tokens = append(tokens, htmlToken{kind: htmlComment, raw: s[:end+7]})
+			s = s[end+7:]
+		case strings.HasPrefix(s, "<!"), strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end == -1 {
+				text(s)
+				return tokens
+			}
+			tokens = append(tokens, htmlToken{kind: htmlDoctype, raw: s[:end+1]})
+			s = s[end+1:]
+		case len(s) > 2 && s[1] == '/' && isASCIILetter(s[2]):
+			end := strings.IndexByte(s, '>')
+			if end == -1 {
+				text(s)
+				return tokens
+			}
+			tokens = append(tokens, htmlToken{kind: htmlEndTag, name: htmlTagName(s[2:end]), raw: s[:end+1]})
+			s = s[end+1:]
+		case len(s) > 1 && isASCIILetter(s[1]):
+			end := htmlTagEnd(s)
+			if end == -1 {
+				text(s)
+				return tokens
+			}
+			raw := s[:end+1]
+			t := htmlToken{
+				kind:        htmlStartTag,
+				name:        htmlTagName(raw[1:]),
+				raw:         raw,
+				selfClosing: strings.HasSuffix(raw, "/>"),
+			}
+			tokens = append(tokens, t)
+			s = s[end+1:]
+			if htmlRawText[t.name] && !t.selfClosing {
+				closing := strings.Index(strings.ToLower(s), "</"+t.name)
+				if closing == -1 {
+					text(s)
+					return tokens
+				}
+				text(s[:closing])
+				s = s[closing:]
+			}
+		default:
+			text("<")
+			s = s[1:]
+		}
+	}
+	return tokens
+}
+
+func htmlTagName(s string) string {
+	end := strings.IndexAny(s, " \t\r\n\f/>")
+	if end == -1 {
+		end = len(s)
+	}
+	return strings.ToLower(s[:end])
+}
+
+// htmlTagEnd returns the position of the > closing a tag, skipping quoted attribute values.
+// If quotes are unbalanced, the first > is used.
+func htmlTagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return strings.IndexByte(s, '>')
+}
+
+// collapseSpaces replaces runs of whitespace with a single space.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeHTMLIndent(buf *bytes.Buffer, tokens []htmlToken) {
+	var (
+		stack []string
+		pre   int // depth of pre elements
+	)
+	line := func(s string) {
+		buf.WriteString(strings.Repeat("  ", len(stack)))
+		buf.WriteString(s)
+		buf.WriteByte('\n')
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.kind {
+		case htmlText:
+			if pre > 0 || htmlRawText[top(stack)] && top(stack) != "title" {
+				for _, l := range strings.Split(strings.Trim(t.raw, "\r\n"), "\n") {
+					if pre > 0 {
+						buf.WriteString(l + "\n")
+					} else if l = strings.TrimSpace(l); l != "" {
+						line(l)
+					}
+				}
+				continue
+			}
+			if s := collapseSpaces(t.raw); s != "" {
+				line(s)
+			}
+		case htmlStartTag:
+			raw := collapseSpaces(t.raw)
+			if t.selfClosing || htmlVoid[t.name] {
+				line(raw)
+				continue
+			}
+			// keep elements containing only text on a single line.
+			if i+2 < len(tokens) && tokens[i+1].kind == htmlText && tokens[i+2].kind == htmlEndTag &&
+				tokens[i+2].name == t.name && !strings.Contains(strings.TrimSpace(tokens[i+1].raw), "\n") {
+				line(raw + strings.TrimSpace(tokens[i+1].raw) + tokens[i+2].raw)
+				i += 2
+				continue
+			}
+			line(raw)
+			stack = append(stack, t.name)
+			if t.name == "pre" {
+				pre++
+			}
+		case htmlEndTag:
+			// unmatched end tags are printed without closing anything.
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j] == t.name {
+					for _, name := range stack[j:] {
+						if name == "pre" {
+							pre--
+						}
+					}
+					stack = stack[:j]
+					break
+				}
+			}
+			line(t.raw)
+		default:
+			line(collapseSpaces(t.raw))
+		}
+	}
+}
+
+func top(stack []string) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	return stack[len(stack)-1]
+}
+
+// htmlHidden elements have no readable text.
+var htmlHidden = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"noscript": true,
+	"svg":      true,
+	"iframe":   true,
+	"object":   true,
+}
+
+// htmlBlock elements start a new line of text.
+var htmlBlock = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "br": true,
+	"center": true, "dd": true, "details": true, "dialog": true, "div": true, "dl": true, "dt": true,
+	"fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"html": true, "li": true, "main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "summary": true, "table": true, "tr": true, "ul": true,
+}
+
+func writeHTMLText(buf *bytes.Buffer, tokens []htmlToken) {
+	var (
+		title   string
+		hidden  []string // open elements hiding their content
+		current strings.Builder
+		lines   []string
+	)
+	flush := func() {
+		if s := collapseSpaces(current.String()); s != "" {
+			lines = append(lines, s)
+		}
+		current.Reset()
+	}
+	for i, t := range tokens {
+		switch t.kind {
+		case htmlText:
+			switch {
+			case i > 0 && tokens[i-1].kind == htmlStartTag && tokens[i-1].name == "title":
+				if title == "" {
+					title = collapseSpaces(html.UnescapeString(t.raw))
+				}
+			case len(hidden) == 0:
+				current.WriteString(html.UnescapeString(t.raw))
+			}
+		case htmlStartTag:
+			if htmlHidden[t.name] && !t.selfClosing {
+				hidden = append(hidden, t.name)
+				continue
+			}
+			if len(hidden) != 0 {
+				continue
+			}
+			if htmlBlock[t.name] || htmlVoid[t.name] && t.name != "img" && t.name != "wbr" {
+				flush()
+			}
+			switch t.name {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				current.WriteString(strings.Repeat("#", int(t.name[1]-'0')) + " ")
+			case "li":
+				current.WriteString("- ")
+			case "td", "th":
+				current.WriteString(" ")
+			}
+		case htmlEndTag:
+			if len(hidden) != 0 {
+				if hidden[len(hidden)-1] == t.name {
+					hidden = hidden[:len(hidden)-1]
+				}
+				continue
+			}
+			if htmlBlock[t.name] {
+				flush()
+			}
+		}
+	}
+	flush()
+	if title != "" {
+		buf.WriteString("title: " + title + "\n")
+	}
+	for _, l := range lines {
+		buf.WriteString(l + "\n")
+	}
+}
@@ -0,0 +1,112 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+const testHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>502 Bad Gateway</title>
+<style>body { color: red; }</style>
+<script>if (a < b) { document.write("<p>hi</p>"); }</script>
+</head>
+<body>
+<center><h1>502 Bad Gateway</h1></center>
+<p>The server returned an <b>invalid</b> response &amp; gave up.</p>
+<ul><li>Retry later</li><li>Contact <a href="/support">support</a></li></ul>
+<hr><center>nginx</center>
+</body>
+</html>`
+
+func TestHTMLFormatterIndent(t *testing.T) {
+	t.Parallel()
+	f := &HTMLFormatter{}
+	if !f.Match("text/html") {
+		t.Error("expected HTMLFormatter to match text/html")
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, []byte(testHTML)); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8">
+    <title>502 Bad Gateway</title>
+    <style>body { color: red; }</style>
+    <script>if (a < b) { document.write("<p>hi</p>"); }</script>
+  </head>
+  <body>
+    <center>
+      <h1>502 Bad Gateway</h1>
+    </center>
+    <p>
+      The server returned an
+      <b>invalid</b>
+      response &amp; gave up.
+    </p>
+    <ul>
+      <li>Retry later</li>
+      <li>
+        Contact
+        <a href="/support">support</a>
+      </li>
+    </ul>
+    <hr>
+    <center>nginx</center>
+  </body>
+</html>`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+}
+
+func TestHTMLFormatterText(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := (&HTMLFormatter{Mode: HTMLText}).Format(&buf, []byte(testHTML)); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `title: 502 Bad Gateway
+# 502 Bad Gateway
+The server returned an invalid response & gave up.
+- Retry later
+- Contact support
+nginx`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+}
+
+func TestHTMLFormatterMalformed(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		mode HTMLMode
+		src  string
+		want string
+	}{
+		{"unclosed", HTMLIndent, "<div><p>one<p>two</div>", "<div>\n  <p>\n    one\n    <p>\n      two\n</div>"},
+		{"stray end tag", HTMLIndent, "</span>text", "</span>\ntext"},
+		{"unterminated tag", HTMLIndent, "a <b class=\"x", "a\n<b class=\"x"},
+		{"unterminated comment", HTMLIndent, "<!-- oops", "<!-- oops"},
+		{"less than", HTMLText, "<p>1 < 2 <3</p>", "1 < 2 <3"},
+		{"unclosed script", HTMLText, "<p>before</p><script>var x", "before"},
+		{"text outside tags", HTMLText, "plain &lt;text&gt;", "plain <text>"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := (&HTMLFormatter{Mode: tc.mode}).Format(&buf, []byte(tc.src)); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -58,6 +58,7 @@ package httpretty
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"encoding/json"
 	"errors"
@@ -66,9 +67,11 @@ import (
 	"net/textproto"
 	"os"
 	"regexp"
+	"strings"
 	"sync"
 
 	"github.com/henvic/httpretty/internal/color"
+	"github.com/henvic/httpretty/internal/header"
 )
 
 // Formatter can be used to format body.
@@ -80,6 +83,52 @@ type Formatter interface {
 	Format(w io.Writer, src []byte) error
 }
 
+// ColorFormatter is a Formatter that can highlight its output using ANSI escape codes.
+//
+// FormatColors is called rather than Format when Logger.Colors is set. Escape sequences other than
+// those setting colors are escaped from its output, unless Logger.SkipEscape is set, but formatters
+// should still escape untrusted text they highlight, as it could set colors of its own.
+type ColorFormatter interface {
+	Formatter
+	FormatColors(w io.Writer, src []byte) error
+}
+
+// Summarizer is a Formatter that can describe a body in a single line, such as the operation of a GraphQL request.
+//
+// The summary of a body is printed before the request or response line.
+type Summarizer interface {
+	Formatter
+	Summarize(mediatype string, src []byte) string
+}
+
+// ResponseFormatter is a Formatter that can format a response body along with the body of its request,
+// such as to pair the results of a JSON-RPC batch with their calls.
+//
+// FormatResponse is called rather than Format for responses whose request body was printed.
+type ResponseFormatter interface {
+	Formatter
+	FormatResponse(w io.Writer, request, src []byte) error
+}
+
+// BinaryFormatter is a Formatter that can format binary data, such as protocol buffers.
+//
+// Bodies matched by a BinaryFormatter whose AcceptsBinary method returns true are passed to it,
+// rather than skipped as binary data.
+type BinaryFormatter interface {
+	Formatter
+	AcceptsBinary() bool
+}
+
+// StreamFormatter is a Formatter that can also format a body incrementally, as it is read.
+//
+// NewStream returns a writer that receives the body as it arrives. Formatted output should be
+// written to w, one record per Write call, so each record can be printed as soon as it is ready.
+// Close is called once the body is fully read or closed.
+type StreamFormatter interface {
+	Formatter
+	NewStream(w io.Writer) io.WriteCloser
+}
+
 // WithHide can be used to protect a request from being exposed.
 func WithHide(ctx context.Context) context.Context {
 	return context.WithValue(ctx, contextHide{}, struct{}{})
@@ -112,11 +161,45 @@ type Logger struct {
 	ResponseBody bool
 
 	// SkipSanitize bypasses sanitizing headers containing credentials (such as Authorization).
+	// See SetHeaderSanitizer to sanitize other headers.
 	SkipSanitize bool
 
+	// DecodeJWT prints the header and claims of JSON Web Tokens found in the Authorization and
+	// Proxy-Authorization headers, and in the access_token and id_token members of JSON bodies,
+	// such as token endpoint responses. Their signature is redacted, and removed from the tokens of bodies.
+	// Tokens aren't decoded when SkipSanitize is set, as they are printed as is.
+	DecodeJWT bool
+
+	// DecodeBasicAuth prints the username of Basic credentials found in the Authorization and
+	// Proxy-Authorization headers, and embedded in request and proxy URLs. Passwords are still redacted,
+	// unless SkipSanitize is set.
+	DecodeBasicAuth bool
+
+	// DetectSecrets redacts headers that look like they carry credentials, such as X-Service-Token
+	// or values with a ghp_ or AKIA prefix, printing which rule detected them. Headers with a sanitizer
+	// set aren't checked. See SetHeaderSanitizer.
+	DetectSecrets bool
+
+	// RedactPII redacts personally identifiable information found in URLs, header values, and bodies,
+	// such as emails and card numbers, printing how many different values of each type were redacted
+	// from each exchange. The Host and X-Forwarded-Host headers and the host of URLs are kept,
+	// and binary bodies are printed as a hexdump without its text column. Use PIIAll to detect all types.
+	// Bodies aren't streamed when it is set.
+	RedactPII PII
+
+	// HashRedactions replaces redacted values with a short keyed digest, such as ‹redacted:7f3a9c›,
+	// rather than a redaction block, so you can tell if two requests used the same token or session cookie
+	// without revealing it. The key is random for each process, unless set with SetRedactionKey.
+	HashRedactions bool
+
 	// Colors set ANSI escape codes that terminals use to print text in different colors.
 	Colors bool
 
+	// SkipEscape prints control characters, such as ESC, and bidirectional text controls found in URLs,
+	// headers, and bodies as is, rather than escaping them as \x1b or \u202e. Untrusted servers and clients can use them
+	// to rewrite your terminal or spoof log lines, so only set it in trusted environments.
+	SkipEscape bool
+
 	// Align HTTP headers.
 	Align bool
 
@@ -127,18 +210,45 @@ type Logger struct {
 
 	// MaxRequestBody the logger can print.
 	// If value is not set and Content-Length is not sent, 4096 bytes is considered.
+	// It also limits the size of decoded bodies, or 4096 bytes if not set.
 	MaxRequestBody int64
 
 	// MaxResponseBody the logger can print.
 	// If value is not set and Content-Length is not sent, 4096 bytes is considered.
+	// It also limits the size of decoded bodies, or 4096 bytes if not set.
 	MaxResponseBody int64
 
+	// HexDump prints up to this many bytes of binary bodies as a hexdump, along with their
+	// detected media type, and the size and SHA-256 checksum of the bytes captured, rather than skipping them.
+	// Only the bytes printed are read from bodies with a binary media type, such as video/mp4.
+	HexDump int
+
+	// StreamResponseBody prints response bodies matched by a StreamFormatter as they are read,
+	// rather than buffering them first. Streamed bodies aren't limited by MaxResponseBody, but their records,
+	// such as NDJSON lines, are skipped if they are longer than it, or than 4096 bytes if it isn't set.
+	StreamResponseBody bool
+
 	mu         sync.Mutex // ensures atomic writes; protects the following fields
 	w          io.Writer
 	filter     Filter
 	skipHeader map[string]struct{}
 	bodyFilter BodyFilter
 	flusher    Flusher
+
+	headerSanitizers map[string]SanitizeHeaderFunc // overrides of the default sanitizers; nil removes them
+	queryParams      []string
+	bodyRedactions   map[string]*BodyRedaction
+	contentDecoders  map[string]ContentDecoder
+	charsetDecoders  map[string]CharsetDecoder
+	redactionKey     []byte
+
+	sanitizers    map[string]header.SanitizeHeaderFunc // cached by getHeaderSanitizers; reset by the setters it depends on
+	sanitizersKey sanitizersKey
+}
+
+// sanitizersKey holds the options the cached header sanitizers were built with.
+type sanitizersKey struct {
+	decodeBasicAuth, decodeJWT, hashRedactions bool
 }
 
 // Filter allows you to skip requests.
@@ -200,6 +310,134 @@ func (l *Logger) SetBodyFilter(f BodyFilter) {
 	l.bodyFilter = f
 }
 
+// SanitizeHeaderFunc sanitizes a header value, such as by redacting credentials.
+type SanitizeHeaderFunc func(value string) string
+
+// RedactHeaderValue replaces the whole value of a header with a redaction block, or a digest if HashRedactions is set.
+// It can be used with SetHeaderSanitizer for headers such as X-Api-Key:
+//
+//	logger.SetHeaderSanitizer("X-Api-Key", logger.RedactHeaderValue)
+func (l *Logger) RedactHeaderValue(value string) string {
+	return l.getRedactor()(value)
+}
+
+// SetHeaderSanitizer allows you to add or replace the function used to sanitize the values of a header,
+// such as X-Api-Key or X-Auth-Token. The Authorization, Proxy-Authorization, Cookie, Set-Cookie, Location,
+// and Referer headers are sanitized by default. Pass nil to remove a sanitizer, including the default ones.
+// If a sanitizer panics, the value is redacted.
+// Sanitizers aren't used when SkipSanitize is set. This method is concurrency safe.
+func (l *Logger) SetHeaderSanitizer(key string, s SanitizeHeaderFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.headerSanitizers == nil {
+		l.headerSanitizers = map[string]SanitizeHeaderFunc{}
+	}
+	l.headerSanitizers[textproto.CanonicalMIMEHeaderKey(key)] = s
+	l.sanitizers = nil
+}
+
+// RedactQueryParams allows you to redact the values of query parameters carrying credentials, such as api_key,
+// from the request line, the request URL, and the Location and Referer headers. The parameters of presigned URLs,
+// such as X-Amz-Signature, and the userinfo of URLs are redacted by default. This method is concurrency safe.
+func (l *Logger) RedactQueryParams(params []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.queryParams = append([]string{}, params...)
+	l.sanitizers = nil
+}
+
+// SetRedactionKey allows you to set the key of the digests replacing redacted values when HashRedactions is set,
+// so that they can be correlated across processes. Pass nil to use a random key for each process.
+// This method is concurrency safe.
+func (l *Logger) SetRedactionKey(key []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.redactionKey = append([]byte(nil), key...)
+	l.sanitizers = nil
+}
+
+// processRedactionKey is used when HashRedactions is set, but no key is.
+var processRedactionKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("httpretty: cannot generate redaction key: " + err.Error())
+	}
+	return key
+})
+
+// getRedactor returns how redacted values are replaced.
+func (l *Logger) getRedactor() header.Redactor {
+	if !l.HashRedactions {
+		return header.Block
+	}
+	l.mu.Lock()
+	key := l.redactionKey
+	l.mu.Unlock()
+	return hmacRedactor(key)
+}
+
+// hmacRedactor returns digests keyed with the given key, or with the process key if it is empty.
+func hmacRedactor(key []byte) header.Redactor {
+	if len(key) == 0 {
+		key = processRedactionKey()
+	}
+	return header.HMAC(key)
+}
+
+func (l *Logger) getQueryParams() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.queryParamsLocked()
+}
+
+// queryParamsLocked must be called with l.mu held.
+func (l *Logger) queryParamsLocked() []string {
+	if len(l.queryParams) == 0 {
+		return header.DefaultQueryParams
+	}
+	return append(append([]string{}, header.DefaultQueryParams...), l.queryParams...)
+}
+
+// SetContentDecoder allows you to decode bodies with a given Content-Encoding, such as br or zstd, before printing them.
+// The gzip, deflate, and zlib encodings are decoded by default. Pass nil to remove a decoder, including the default ones.
+// This method is concurrency safe.
+func (l *Logger) SetContentDecoder(encoding string, d ContentDecoder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.contentDecoders == nil {
+		l.contentDecoders = map[string]ContentDecoder{}
+		for e, dd := range defaultContentDecoders {
+			l.contentDecoders[e] = dd
+		}
+	}
+	encoding = strings.ToLower(encoding)
+	if d == nil {
+		delete(l.contentDecoders, encoding)
+		return
+	}
+	l.contentDecoders[encoding] = d
+}
+
+// SetCharsetDecoder allows you to transcode bodies with a given charset, such as Shift_JIS, to UTF-8 before printing them.
+// The UTF-16 (LE and BE), ISO-8859-1, and Windows-1252 charsets are decoded by default.
+// Pass nil to remove a decoder, including the default ones. This method is concurrency safe.
+func (l *Logger) SetCharsetDecoder(charset string, d CharsetDecoder) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.charsetDecoders == nil {
+		l.charsetDecoders = map[string]CharsetDecoder{}
+		for c, dd := range defaultCharsetDecoders {
+			l.charsetDecoders[c] = dd
+		}
+	}
+	charset = strings.ToLower(charset)
+	if d == nil {
+		delete(l.charsetDecoders, charset)
+		return
+	}
+	l.charsetDecoders[charset] = d
+}
+
 // SetOutput sets the output destination for the logger.
 func (l *Logger) SetOutput(w io.Writer) {
 	l.mu.Lock()
@@ -236,6 +474,65 @@ func (l *Logger) getBodyFilter() BodyFilter {
 	return f
 }
 
+func (l *Logger) getContentDecoder(encoding string) ContentDecoder {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.contentDecoders == nil {
+		return defaultContentDecoders[encoding]
+	}
+	return l.contentDecoders[encoding]
+}
+
+func (l *Logger) getCharsetDecoder(charset string) CharsetDecoder {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.charsetDecoders == nil {
+		return defaultCharsetDecoders[charset]
+	}
+	return l.charsetDecoders[charset]
+}
+
+// getHeaderSanitizers returns the sanitizers to use, with the default ones decoding credentials
+// if DecodeBasicAuth or DecodeJWT are set, and redacting the query parameters set with RedactQueryParams.
+// The sanitizers are cached until the options or setters they depend on change. The map must not be modified.
+func (l *Logger) getHeaderSanitizers() map[string]header.SanitizeHeaderFunc {
+	key := sanitizersKey{
+		decodeBasicAuth: l.DecodeBasicAuth,
+		decodeJWT:       l.DecodeJWT,
+		hashRedactions:  l.HashRedactions,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.headerSanitizers) == 0 && len(l.queryParams) == 0 && key == (sanitizersKey{}) {
+		return header.DefaultSanitizers
+	}
+	if l.sanitizers != nil && l.sanitizersKey == key {
+		return l.sanitizers
+	}
+	params := l.queryParamsLocked()
+	redact := header.Block
+	if key.hashRedactions {
+		redact = hmacRedactor(l.redactionKey)
+	}
+	m := header.Sanitizers(redact)
+	m["Location"] = header.URLSanitizer(params, l.DecodeBasicAuth, redact)
+	m["Referer"] = header.URLSanitizer(params, l.DecodeBasicAuth, redact)
+	if l.DecodeBasicAuth || l.DecodeJWT {
+		auth := header.DecodingAuthorizationSanitizer(l.DecodeBasicAuth, l.DecodeJWT, redact)
+		m["Authorization"] = auth
+		m["Proxy-Authorization"] = auth
+	}
+	for k, s := range l.headerSanitizers {
+		if s == nil {
+			delete(m, k)
+			continue
+		}
+		m[k] = header.SanitizeHeaderFunc(s)
+	}
+	l.sanitizers, l.sanitizersKey = m, key
+	return m
+}
+
 func (l *Logger) cloneSkipHeader() map[string]struct{} {
 	l.mu.Lock()
 	skipped := l.skipHeader
@@ -290,10 +587,7 @@ func (r roundTripper) RoundTrip(req *http.Request) (resp *http.Response, err err
 	transport, ok := tripper.(*http.Transport)
 	// If proxy is used, then print information about proxy server
 	if ok && transport.Proxy != nil {
-		proxyUrl, err := transport.Proxy(req)
-		if proxyUrl != nil && err == nil {
-			p.printf("* Using proxy: %s\n", p.format(color.FgBlue, proxyUrl.String()))
-		}
+		p.printProxy(transport, req)
 	}
 	if ok && transport.TLSClientConfig != nil {
 		tlsClientConfig = transport.TLSClientConfig
@@ -307,6 +601,7 @@ func (r roundTripper) RoundTrip(req *http.Request) (resp *http.Response, err err
 		// please remember http.Request.TLS is ignored by the HTTP client.
 		p.printOutgoingClientTLS(tlsClientConfig)
 	}
+	defer p.printPIISummary()
 	p.printRequest(req)
 	defer func() {
 		if err != nil {
@@ -356,6 +651,7 @@ func (h httpHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		p.printTLSInfo(req.TLS, true)
 		p.printIncomingClientTLS(req.TLS)
 	}
+	defer p.printPIISummary()
 	p.printRequest(req)
 	rec := &responseRecorder{
 		ResponseWriter:  w,
@@ -363,6 +659,21 @@ func (h httpHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		maxReadableBody: l.MaxResponseBody,
 		buf:             &bytes.Buffer{},
 	}
+	if l.ResponseBody {
+		rec.bodyLimit = func() int64 {
+			contentType := rec.Header().Get("Content-Type")
+			if l.HexDump > 0 && p.skipBinaryContentType(contentType) {
+				rec.keepHead = true
+				return p.hexDumpLimit(l.MaxResponseBody)
+			}
+			return p.bodyLimit(contentType, l.MaxResponseBody)
+		}
+	}
+	if l.ResponseBody && l.StreamResponseBody {
+		rec.startStream = func() *bodyStream {
+			return p.startServerResponseStream(req, rec)
+		}
+	}
 	defer p.printServerResponse(req, rec)
 	h.next.ServeHTTP(rec, req)
 }
@@ -376,12 +687,14 @@ func (l *Logger) PrintRequest(req *http.Request) {
 		return
 	}
 	p.printRequest(req)
+	p.printPIISummary()
 }
 
 // PrintResponse prints a response.
 func (l *Logger) PrintResponse(resp *http.Response) {
 	var p = printer{logger: l}
 	p.printResponse(resp)
+	p.printPIISummary()
 }
 
 // JSONFormatter helps you read unreadable JSON documents.
@@ -0,0 +1,298 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// ImageFormatter prints a summary of PNG, JPEG, and GIF images, rather than skipping them as binary data.
+//
+// The format, dimensions, and color model are read without decoding the image data.
+// The number of frames of GIF images and the EXIF orientation of JPEG images are printed too.
+type ImageFormatter struct {
+	// Thumbnail renders a small preview of the image with ANSI block characters when Logger.Colors is set.
+	// It requires a terminal with true color support.
+	Thumbnail bool
+
+	// ThumbnailWidth in columns. Defaults to 32.
+	ThumbnailWidth int
+}
+
+// Match PNG, JPEG, and GIF media types.
+func (i *ImageFormatter) Match(mediatype string) bool {
+	switch mediatype {
+	case "image/png", "image/jpeg", "image/jpg", "image/pjpeg", "image/gif":
+		return true
+	}
+	return false
+}
+
+// AcceptsBinary data.
+func (i *ImageFormatter) AcceptsBinary() bool {
+	return true
+}
+
+// Format images.
+func (i *ImageFormatter) Format(w io.Writer, src []byte) error {
+	return i.format(w, src, false)
+}
+
+// FormatColors formats images, including a thumbnail if enabled.
+func (i *ImageFormatter) FormatColors(w io.Writer, src []byte) error {
+	return i.format(w, src, i.Thumbnail)
+}
+
+func (i *ImageFormatter) format(w io.Writer, src []byte, thumbnail bool) error {
+	cfg, format, err := decodeImageConfig(src)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "format: %s\n", format)
+	fmt.Fprintf(&buf, "dimensions: %dx%d\n", cfg.Width, cfg.Height)
+	fmt.Fprintf(&buf, "color model: %s\n", colorModelName(cfg.ColorModel))
+	switch format {
+	case "gif":
+		if frames, err := gifFrames(src); err == nil {
+			fmt.Fprintf(&buf, "frames: %d\n", frames)
+		}
+	case "jpeg":
+		if o := exifOrientation(src); o != 0 {
+			fmt.Fprintf(&buf, "orientation: %s (%d)\n", orientationName(o), o)
+		}
+	}
+	if thumbnail {
+		i.writeThumbnail(&buf, src, cfg, format)
+	}
+	_, err = w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// decodeImageConfig uses the decoders directly, rather than image.DecodeConfig,
+// to avoid depending on which formats were registered by the program.
+func decodeImageConfig(src []byte) (image.Config, string, error) {
+	r := bytes.NewReader(src)
+	switch {
+	case bytes.HasPrefix(src, []byte("\x89PNG\r\n\x1a\n")):
+		cfg, err := png.DecodeConfig(r)
+		return cfg, "png", err
+	case bytes.HasPrefix(src, []byte("\xff\xd8")):
+		cfg, err := jpeg.DecodeConfig(r)
+		return cfg, "jpeg", err
+	case bytes.HasPrefix(src, []byte("GIF87a")), bytes.HasPrefix(src, []byte("GIF89a")):
+		cfg, err := gif.DecodeConfig(r)
+		return cfg, "gif", err
+	}
+	return image.Config{}, "", image.ErrFormat
+}
+
+func decodeImage(src []byte, format string) (image.Image, error) {
+	r := bytes.NewReader(src)
+	switch format {
+	case "png":
+		return png.Decode(r)
+	case "jpeg":
+		return jpeg.Decode(r)
+	}
+	return gif.Decode(r)
+}
+
+func colorModelName(m color.Model) string {
+	if p, ok := m.(color.Palette); ok {
+		if len(p) == 0 {
+			// GIF images may only have local color tables.
+			return "paletted"
+		}
+		return fmt.Sprintf("paletted (%d colors)", len(p))
+	}
+	switch m {
+	case color.RGBAModel:
+		return "RGBA"
+	case color.RGBA64Model:
+		return "RGBA64"
+	case color.NRGBAModel:
+		return "NRGBA"
+	case color.NRGBA64Model:
+		return "NRGBA64"
+	case color.AlphaModel:
+		return "alpha"
+	case color.Alpha16Model:
+		return "alpha16"
+	case color.GrayModel:
+		return "gray"
+	case color.Gray16Model:
+		return "gray16"
+	case color.YCbCrModel:
+		return "YCbCr"
+	case color.CMYKModel:
+		return "CMYK"
+	}
+	return "unknown"
+}
+
+// gifFrames counts the image descriptors of a GIF, skipping over their data without decoding it.
+func gifFrames(src []byte) (int, error) {
+	errTruncated := io.ErrUnexpectedEOF
+	if len(src) < 13 {
+		return 0, errTruncated
+	}
+	pos := 13
+	if flags := src[10]; flags&0x80 != 0 {
+		pos += 3 << (flags&0x07 + 1) // global color table
+	}
+	skipSubBlocks := func() error {
+		for {
+			if pos >= len(src) {
+				return errTruncated
+			}
+			n := int(src[pos])
+			pos += n + 1
+			if n == 0 {
+				return nil
+			}
+		}
+	}
+	var frames int
+	for pos < len(src) {
+		switch src[pos] {
+		case 0x21: // extension
+			pos += 2
+			if err := skipSubBlocks(); err != nil {
+				return frames, err
+			}
+		case 0x2c: // image descriptor
+			if pos+10 > len(src) {
+				return frames, errTruncated
+			}
+			frames++
+			flags := src[pos+9]
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << (flags&0x07 + 1) // local color table
+			}
+			pos++ // LZW minimum code size
+			if err := skipSubBlocks(); err != nil {
+				return frames, err
+			}
+		case 0x3b: // trailer
+			return frames, nil
+		default:
+			return frames, fmt.Errorf("gif: unknown block type 0x%02x", src[pos])
+		}
+	}
+	return frames, errTruncated
+}
+
+// exifOrientation returns the orientation tag found in the EXIF data of a JPEG image, or 0.
+func exifOrientation(src []byte) int {
+	pos := 2
+	for pos+4 <= len(src) && src[pos] == 0xff {
+		marker := src[pos+1]
+		size := int(binary.BigEndian.Uint16(src[pos+2:]))
+		if marker == 0xda || size < 2 || pos+2+size > len(src) { // start of scan
+			return 0
+		}
+		segment := src[pos+4 : pos+2+size]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 0
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) || ifd < 0 {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+func orientationName(o int) string {
+	switch o {
+	case 1:
+		return "normal"
+	case 2:
+		return "mirrored horizontally"
+	case 3:
+		return "rotated 180°"
+	case 4:
+		return "mirrored vertically"
+	case 5:
+		return "mirrored horizontally and rotated 270° clockwise"
+	case 6:
+		return "rotated 90° clockwise"
+	case 7:
+		return "mirrored horizontally and rotated 90° clockwise"
+	case 8:
+		return "rotated 270° clockwise"
+	}
+	return "unknown"
+}
+
+// maxThumbnailPixels limits the size of images decoded for rendering thumbnails.
+const maxThumbnailPixels = 4096 * 4096
+
+// writeThumbnail renders the image using upper half blocks, with the top pixel as the foreground color,
+// and the bottom pixel as the background color.
+func (i *ImageFormatter) writeThumbnail(buf *bytes.Buffer, src []byte, cfg image.Config, format string) {
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxThumbnailPixels {
+		return
+	}
+	img, err := decodeImage(src, format)
+	if err != nil {
+		return
+	}
+	cols := i.ThumbnailWidth
+	if cols <= 0 {
+		cols = 32
+	}
+	if cols > cfg.Width {
+		cols = cfg.Width
+	}
+	rows := (cfg.Height*cols/cfg.Width + 1) / 2 * 2
+	if rows == 0 {
+		rows = 2
+	}
+	b := img.Bounds()
+	pixel := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(b.Min.X+x*b.Dx()/cols, b.Min.Y+y*b.Dy()/rows)).(color.RGBA)
+	}
+	for y := 0; y < rows; y += 2 {
+		for x := 0; x < cols; x++ {
+			top, bottom := pixel(x, y), pixel(x, y+1)
+			fmt.Fprintf(buf, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		buf.WriteString("\x1b[0m\n")
+	}
+}
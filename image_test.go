@@ -0,0 +1,105 @@
+package httpretty
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testPNG() []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+		img.Set(x, 1, color.NRGBA{B: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func testGIF() []byte {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+	for i := 0; i < 3; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 5, 5), palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	_ = gif.EncodeAll(&buf, anim)
+	return buf.Bytes()
+}
+
+// testJPEG returns a JPEG image with an EXIF segment setting its orientation.
+func testJPEG(orientation byte) []byte {
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 3, 7)), nil)
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00")
+	tiff = append(tiff, orientation, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xff, 0xe1, 0, byte(len(segment) + 2)}, segment...)
+	b := buf.Bytes()
+	return append(append(append([]byte{}, b[:2]...), app1...), b[2:]...)
+}
+
+func TestImageFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		src  []byte
+		want string
+	}{
+		{"png", testPNG(), "format: png\ndimensions: 4x2\ncolor model: RGBA"},
+		{"gif", testGIF(), "format: gif\ndimensions: 5x5\ncolor model: paletted\nframes: 3"},
+		{"jpeg", testJPEG(6), "format: jpeg\ndimensions: 3x7\ncolor model: gray\norientation: rotated 90° clockwise (6)"},
+	}
+	f := &ImageFormatter{}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			if !f.Match("image/"+tc.desc) || !f.AcceptsBinary() {
+				t.Errorf("expected ImageFormatter to match image/%s binary data", tc.desc)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, tc.src); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImageFormatterInvalid(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := (&ImageFormatter{}).Format(&buf, []byte("not an image")); err != image.ErrFormat {
+		t.Errorf("got format error = %v, wanted %v", err, image.ErrFormat)
+	}
+	if err := (&ImageFormatter{}).Format(&buf, testPNG()[:20]); err == nil {
+		t.Error("expected error formatting truncated image")
+	}
+}
+
+func TestImageFormatterThumbnail(t *testing.T) {
+	t.Parallel()
+	f := &ImageFormatter{Thumbnail: true}
+	var buf bytes.Buffer
+	if err := f.FormatColors(&buf, testPNG()); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := "format: png\ndimensions: 4x2\ncolor model: RGBA\n" +
+		strings.Repeat("\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m▀", 4) + "\x1b[0m"
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %q, wanted %q", got, want)
+	}
+	buf.Reset()
+	if err := f.Format(&buf, testPNG()); err != nil || strings.Contains(buf.String(), "▀") {
+		t.Errorf("expected no thumbnail without colors, got %q (%v)", buf.String(), err)
+	}
+}
@@ -7,8 +7,10 @@ package color
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Attribute defines a single SGR (Select Graphic Rendition) code.
@@ -76,10 +78,7 @@ const (
 	BgHiWhite
 )
 
-const (
-	escape   = "\x1b"
-	unescape = "\\x1b"
-)
+const escape = "\x1b"
 
 // Format text for terminal.
 // You can pass an arbitrary number of Attribute or []Attribute followed by any other values,
@@ -142,9 +141,82 @@ func StripAttributes(s ...interface{}) (raw string) {
 	return fmt.Sprint(s[in:]...)
 }
 
-// Escape text for terminal.
+// Escape text for terminal, so that it cannot move the cursor, rewrite what was printed, or spoof text.
+// Control characters, such as ESC (starting CSI and OSC sequences), CR, and backspace, and bidirectional text
+// controls are replaced with Go escape sequences, such as \x1b, \r, and \u202e. Newlines and tabs are kept.
 func Escape(s string) string {
-	return strings.Replace(s, escape, unescape, -1)
+	if strings.IndexFunc(s, needsEscape) == -1 {
+		return s
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteByte(s[0]) // keep invalid bytes as is
+		case needsEscape(r):
+			b.WriteString(escapeRune(r))
+		default:
+			b.WriteString(s[:size])
+		}
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// sgrRE matches SGR escape sequences, which only set colors and other text attributes.
+var sgrRE = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// EscapeExceptColors escapes text for terminal like Escape, but keeps SGR escape sequences setting colors,
+// such as those of text highlighted with Format.
+func EscapeExceptColors(s string) string {
+	if strings.IndexFunc(s, needsEscape) == -1 {
+		return s
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range sgrRE.FindAllStringIndex(s, -1) {
+		b.WriteString(Escape(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(Escape(s[last:]))
+	return b.String()
+}
+
+// needsEscape checks if a rune is a C0 or C1 control character, other than newline and tab,
+// or a bidirectional text control.
+func needsEscape(r rune) bool {
+	switch {
+	case r == '\n', r == '\t':
+		return false
+	case r < 0x20, 0x7f <= r && r <= 0x9f:
+		return true
+	case r == 0x061c, r == 0x200e, r == 0x200f, 0x202a <= r && r <= 0x202e, 0x2066 <= r && r <= 0x2069:
+		return true
+	}
+	return false
+}
+
+func escapeRune(r rune) string {
+	switch r {
+	case '\a':
+		return `\a`
+	case '\b':
+		return `\b`
+	case '\f':
+		return `\f`
+	case '\r':
+		return `\r`
+	case '\v':
+		return `\v`
+	}
+	if r < utf8.RuneSelf {
+		return fmt.Sprintf(`\x%02x`, r)
+	}
+	return fmt.Sprintf(`\u%04x`, r)
 }
 
 // sequence returns a formated SGR sequence to be plugged into a "\x1b[...m"
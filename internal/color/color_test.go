@@ -86,6 +86,24 @@ func TestEscape(t *testing.T) {
 	}
 }
 
+func TestEscapeExceptColors(t *testing.T) {
+	testCases := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{Format(FgRed, "error"), Format(FgRed, "error")},
+		{"\x1b[38;2;1;2;3m\x1b[48;2;4;5;6m▀\x1b[0m", "\x1b[38;2;1;2;3m\x1b[48;2;4;5;6m▀\x1b[0m"},
+		{"\x1b[31m\x1b]0;pwned\x07\x1b[0m", "\x1b[31m\\x1b]0;pwned\\a\x1b[0m"},
+		{"\x1b[2J\x1b[1;1H\rspoofed", `\x1b[2J\x1b[1;1H\rspoofed`},
+		{"\x1b[31m\u202eevil\x1b[0m", "\x1b[31m\\u202eevil\x1b[0m"},
+	}
+	for _, tc := range testCases {
+		if got := EscapeExceptColors(tc.in); got != tc.want {
+			t.Errorf("EscapeExceptColors(%q) = %q, wanted %q", tc.in, got, tc.want)
+		}
+	}
+}
+
 func TestStripAttributes(t *testing.T) {
 	want := "this is a regular string"
 	got := StripAttributes(FgCyan, []Attribute{FgBlack}, "this is a regular string")
@@ -123,3 +141,25 @@ func TestStripAttributesWithExtraColorAttribute(t *testing.T) {
 		t.Errorf("StripAttributes(input) = %s, wanted %s", got, want)
 	}
 }
+
+func TestEscapeControlCharacters(t *testing.T) {
+	testCases := []struct {
+		in, want string
+	}{
+		{"plain text\n\twith newlines and tabs", "plain text\n\twith newlines and tabs"},
+		{"\x1b]0;pwned\x07title", `\x1b]0;pwned\atitle`},
+		{"ok\rfake line", `ok\rfake line`},
+		{"abc\b\b\bxyz", `abc\b\b\bxyz`},
+		{"\x00\x7f", `\x00\x7f`},
+		{"\u009b31m", `\u009b31m`},
+		{"invoice_\u202efdp.exe", `invoice_\u202efdp.exe`},
+		{"\u2066isolated\u2069", `\u2066isolated\u2069`},
+		{"héllo, 世界", "héllo, 世界"},
+		{"invalid \xff\x1b", "invalid \xff\\x1b"},
+	}
+	for _, tc := range testCases {
+		if got := Escape(tc.in); got != tc.want {
+			t.Errorf("Escape(%q) = %q, wanted %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package header
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodingAuthorizationSanitizer returns a sanitizer for Authorization and Proxy-Authorization headers
+// that decodes the username of Basic credentials and the header and claims of JSON Web Tokens, if enabled,
+// while still redacting passwords and signatures.
+// Other credentials are sanitized with AuthorizationSanitizer.
+func DecodingAuthorizationSanitizer(basic, jwt bool, redact Redactor) SanitizeHeaderFunc {
+	sanitize := AuthorizationSanitizer(redact)
+	return func(unsafe string) string {
+		scheme, credentials, ok := strings.Cut(unsafe, " ")
+		if !ok {
+			return sanitize(unsafe)
+		}
+		if basic && strings.EqualFold(scheme, "Basic") {
+			if decoded, ok := BasicCredentials(credentials, redact); ok {
+				return scheme + " " + decoded
+			}
+		}
+		if jwt {
+			if decoded, ok := JWT(credentials, time.Now(), redact); ok {
+				return scheme + " " + decoded
+			}
+		}
+		return sanitize(unsafe)
+	}
+}
+
+// BasicCredentials decodes the username of Basic credentials, redacting their password.
+func BasicCredentials(credentials string, redact Redactor) (string, bool) {
+	b, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return "", false
+	}
+	user, password, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return "", false
+	}
+	return "user=" + quoteValue(user) + " password=" + redact(password), true
+}
+
+// quoteValue quotes values that are empty, contain spaces, quotes, commas, or control characters.
+func quoteValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\",") || !strconv.CanBackquote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,31 @@
+package header
+
+import "testing"
+
+func TestDecodingAuthorizationSanitizer(t *testing.T) {
+	token := testJWT(`{"alg":"HS256"}`, `{"sub":"svc-billing"}`)
+	testCases := []struct {
+		desc       string
+		basic, jwt bool
+		unsafe     string
+		want       string
+	}{
+		{"basic", true, false, "Basic c3ZjLWJpbGxpbmc6aHVudGVyMg==", "Basic user=svc-billing password=████████████████████"},
+		{"basic with spaces", true, false, "Basic amFuZSBkb2U6c2VjcmV0", `Basic user="jane doe" password=████████████████████`},
+		{"basic empty password", true, false, "Basic c3ZjLWJpbGxpbmc6", "Basic user=svc-billing password="},
+		{"basic disabled", false, true, "Basic c3ZjLWJpbGxpbmc6aHVudGVyMg==", "Basic ████████████████████"},
+		{"basic without colon", true, false, "Basic c3ZjLWJpbGxpbmc=", "Basic ████████████████████"},
+		{"basic invalid", true, false, "Basic !!!", "Basic ████████████████████"},
+		{"bearer", true, true, "Bearer foo", "Bearer ████████████████████"},
+		{"jwt", true, true, "Bearer " + token, "Bearer JWT alg=HS256 sub=svc-billing signature=████████████████████"},
+		{"jwt disabled", true, false, "Bearer " + token, "Bearer ████████████████████"},
+		{"scheme only", true, true, "Negotiate", "Negotiate"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if got := DecodingAuthorizationSanitizer(tc.basic, tc.jwt, Block)(tc.unsafe); got != tc.want {
+				t.Errorf("got %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@
 package header
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"strings"
@@ -35,80 +38,112 @@ func sanitize(s SanitizeHeaderFunc, values []string) []string {
 }
 
 // DefaultSanitizers contains a list of sanitizers to be used for common headers.
-var DefaultSanitizers = map[string]SanitizeHeaderFunc{
-	"Authorization":       AuthorizationSanitizer,
-	"Set-Cookie":          SetCookieSanitizer,
-	"Cookie":              CookieSanitizer,
-	"Proxy-Authorization": AuthorizationSanitizer,
+var DefaultSanitizers = Sanitizers(Block)
+
+// Sanitizers returns the sanitizers to be used for common headers, redacting values with the given Redactor.
+func Sanitizers(redact Redactor) map[string]SanitizeHeaderFunc {
+	return map[string]SanitizeHeaderFunc{
+		"Authorization":       AuthorizationSanitizer(redact),
+		"Set-Cookie":          SetCookieSanitizer(redact),
+		"Cookie":              CookieSanitizer(redact),
+		"Proxy-Authorization": AuthorizationSanitizer(redact),
+		"Location":            URLSanitizer(DefaultQueryParams, false, redact),
+		"Referer":             URLSanitizer(DefaultQueryParams, false, redact),
+	}
 }
 
 // SanitizeHeaderFunc implements sanitization for a header value.
 type SanitizeHeaderFunc func(string) string
 
-// AuthorizationSanitizer is used to sanitize Authorization and Proxy-Authorization headers.
-func AuthorizationSanitizer(unsafe string) string {
-	if unsafe == "" {
-		return ""
-	}
+// AuthorizationSanitizer returns a sanitizer for Authorization and Proxy-Authorization headers.
+func AuthorizationSanitizer(redact Redactor) SanitizeHeaderFunc {
+	return func(unsafe string) string {
+		if unsafe == "" {
+			return ""
+		}
 
-	directives := strings.SplitN(unsafe, " ", 2)
+		directives := strings.SplitN(unsafe, " ", 2)
 
-	l := 0
+		var credentials string
 
-	if len(directives) > 1 {
-		l = len(directives[1])
-	}
+		if len(directives) > 1 {
+			credentials = directives[1]
+		}
 
-	if l == 0 {
-		return directives[0]
-	}
+		if credentials == "" {
+			return directives[0]
+		}
 
-	return directives[0] + " " + redact(l)
+		return directives[0] + " " + redact(credentials)
+	}
 }
 
-// SetCookieSanitizer is used to sanitize Set-Cookie header.
-func SetCookieSanitizer(unsafe string) string {
-	directives := strings.SplitN(unsafe, ";", 2)
+// SetCookieSanitizer returns a sanitizer for the Set-Cookie header.
+func SetCookieSanitizer(redact Redactor) SanitizeHeaderFunc {
+	return func(unsafe string) string {
+		directives := strings.SplitN(unsafe, ";", 2)
 
-	cookie := strings.SplitN(directives[0], "=", 2)
+		cookie := strings.SplitN(directives[0], "=", 2)
 
-	l := 0
+		var value string
 
-	if len(cookie) > 1 {
-		l = len(cookie[1])
-	}
+		if len(cookie) > 1 {
+			value = cookie[1]
+		}
 
-	if len(directives) == 2 {
-		return fmt.Sprintf("%s=%s; %s", cookie[0], redact(l), strings.TrimPrefix(directives[1], " "))
-	}
+		if len(directives) == 2 {
+			return fmt.Sprintf("%s=%s; %s", cookie[0], redact(value), strings.TrimPrefix(directives[1], " "))
+		}
 
-	return fmt.Sprintf("%s=%s", cookie[0], redact(l))
+		return fmt.Sprintf("%s=%s", cookie[0], redact(value))
+	}
 }
 
-// CookieSanitizer is used to sanitize Cookie header.
-func CookieSanitizer(unsafe string) string {
-	cookies := strings.Split(unsafe, ";")
+// CookieSanitizer returns a sanitizer for the Cookie header.
+func CookieSanitizer(redact Redactor) SanitizeHeaderFunc {
+	return func(unsafe string) string {
+		cookies := strings.Split(unsafe, ";")
 
-	var list []string
+		var list []string
 
-	for _, unsafeCookie := range cookies {
-		cookie := strings.SplitN(unsafeCookie, "=", 2)
-		l := 0
+		for _, unsafeCookie := range cookies {
+			cookie := strings.SplitN(unsafeCookie, "=", 2)
 
-		if len(cookie) > 1 {
-			l = len(cookie[1])
+			var value string
+
+			if len(cookie) > 1 {
+				value = cookie[1]
+			}
+
+			list = append(list, fmt.Sprintf("%s=%s", cookie[0], redact(value)))
 		}
 
-		list = append(list, fmt.Sprintf("%s=%s", cookie[0], redact(l)))
+		return strings.Join(list, "; ")
 	}
-
-	return strings.Join(list, "; ")
 }
 
-func redact(count int) string {
-	if count == 0 {
+// Redactor replaces a sensitive value, such as a password or a token. Empty values are kept empty.
+type Redactor func(unsafe string) string
+
+// Block redacts a value with a redaction block of fixed length, so that its length isn't revealed either.
+func Block(unsafe string) string {
+	if unsafe == "" {
 		return ""
 	}
 
 	return "████████████████████"
 }
+
+// HMAC returns a Redactor replacing values with a short digest keyed with the given key, such as ‹redacted:7f3a9c›,
+// so that identical values can be correlated without revealing them.
+func HMAC(key []byte) Redactor {
+	return func(unsafe string) string {
+		if unsafe == "" {
+			return ""
+		}
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(unsafe))
+		return "‹redacted:" + hex.EncodeToString(mac.Sum(nil))[:6] + "›"
+	}
+}
@@ -3,6 +3,7 @@ package header
 import (
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -38,3 +39,50 @@ func TestSanitize(t *testing.T) {
 		t.Errorf("Sanitized headers doesn't match expected value: wanted %+v, got %+v instead", want, got)
 	}
 }
+
+func TestBlock(t *testing.T) {
+	if got, want := Block("secret"), "████████████████████"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got := Block(""); got != "" {
+		t.Errorf("got %q, wanted empty value", got)
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	redact := HMAC([]byte("key"))
+	got := redact("secret")
+	if !strings.HasPrefix(got, "‹redacted:") || !strings.HasSuffix(got, "›") || len(got) != len("‹redacted:7f3a9c›") {
+		t.Errorf("got %q, wanted a short digest", got)
+	}
+	if again := redact("secret"); again != got {
+		t.Errorf("got %q for the same value, wanted %q", again, got)
+	}
+	if other := redact("other"); other == got {
+		t.Errorf("got %q for different values", other)
+	}
+	if otherKey := HMAC([]byte("other key"))("secret"); otherKey == got {
+		t.Errorf("got %q for different keys", otherKey)
+	}
+	if got := redact(""); got != "" {
+		t.Errorf("got %q, wanted empty value", got)
+	}
+}
+
+func TestSanitizersHMAC(t *testing.T) {
+	var headers = http.Header{}
+	headers.Add("Cookie", "session=abc")
+	headers.Add("Cookie", "theme=dark")
+	headers.Add("Set-Cookie", "session=abc; Secure")
+	headers.Add("Authorization", "Bearer abc")
+	redact := HMAC([]byte("key"))
+	token := redact("abc")
+	want := http.Header{
+		"Cookie":        []string{"session=" + token, "theme=" + redact("dark")},
+		"Set-Cookie":    []string{"session=" + token + "; Secure"},
+		"Authorization": []string{"Bearer " + token},
+	}
+	if got := Sanitize(Sanitizers(redact), headers); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, wanted %+v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package header
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// jwtClaims are printed in this order.
+var jwtClaims = []string{"iss", "sub", "aud", "iat", "exp"}
+
+// JWT decodes the header (alg, kid) and registered claims (iss, sub, aud, iat, exp) of a JSON Web Token,
+// printing times relative to now, and redacting its signature.
+// It returns false if the token isn't a signed or unsecured JWT.
+func JWT(token string, now time.Time, redact Redactor) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+	h, ok := decodeJWTPart(parts[0])
+	if !ok {
+		return "", false
+	}
+	if _, ok := h["alg"]; !ok {
+		return "", false
+	}
+	claims, ok := decodeJWTPart(parts[1])
+	if !ok {
+		return "", false
+	}
+	var fields = []string{"JWT"}
+	for _, name := range []string{"alg", "kid"} {
+		if v, ok := h[name]; ok {
+			fields = append(fields, name+"="+jwtValue(v))
+		}
+	}
+	for _, name := range jwtClaims {
+		v, ok := claims[name]
+		if !ok {
+			continue
+		}
+		field := name + "=" + jwtValue(v)
+		if t, ok := jwtTime(v); ok && (name == "iat" || name == "exp") {
+			field = name + "=" + t.UTC().Format(time.RFC3339) + " (" + relativeTime(name, t, now) + ")"
+		}
+		fields = append(fields, field)
+	}
+	if parts[2] != "" {
+		fields = append(fields, "signature="+redact(parts[2]))
+	}
+	return strings.Join(fields, " "), true
+}
+
+// RedactJWTSignature redacts the signature of a JSON Web Token.
+func RedactJWTSignature(token string, redact Redactor) string {
+	i := strings.LastIndexByte(token, '.')
+	if i == -1 || i == len(token)-1 {
+		return token
+	}
+	return token[:i+1] + redact(token[i+1:])
+}
+
+func decodeJWTPart(part string) (map[string]json.RawMessage, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return nil, false
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil || m == nil {
+		return nil, false
+	}
+	return m, true
+}
+
+// jwtValue prints strings and lists of strings (used by aud) unquoted, unless they contain spaces.
+func jwtValue(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return quoteValue(s)
+	}
+	var list []string
+	if err := json.Unmarshal(v, &list); err == nil {
+		for i, s := range list {
+			list[i] = quoteValue(s)
+		}
+		return strings.Join(list, ",")
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, v); err != nil {
+		return string(v)
+	}
+	return compact.String()
+}
+
+// jwtTime decodes a NumericDate: the number of seconds since the Unix epoch.
+func jwtTime(v json.RawMessage) (time.Time, bool) {
+	var f float64
+	if err := json.Unmarshal(v, &f); err != nil || math.Abs(f) > 1<<40 {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+func relativeTime(claim string, t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case claim == "iat" && d >= 0:
+		return "issued " + humanDuration(d) + " ago"
+	case claim == "iat":
+		return "issued in the future, in " + humanDuration(-d)
+	case d >= 0:
+		return "expired " + humanDuration(d) + " ago"
+	}
+	return "expires in " + humanDuration(-d)
+}
+
+// humanDuration rounds a duration down to its most significant units, such as 3m, 2h5m, or 4d.
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		h, m := int(d/time.Hour), int(d%time.Hour/time.Minute)
+		if m == 0 {
+			return fmt.Sprintf("%dh", h)
+		}
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+	return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+}
@@ -0,0 +1,70 @@
+package header
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func testJWT(header, claims string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(header)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2lnbmF0dXJl"
+}
+
+func TestJWT(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 3, 30, 0, time.UTC)
+	testCases := []struct {
+		desc  string
+		token string
+		want  string
+		ok    bool
+	}{
+		{
+			desc:  "expired",
+			token: testJWT(`{"alg":"RS256","kid":"key-1","typ":"JWT"}`, `{"iss":"https://auth.example.com","sub":"svc-billing","aud":["api","billing"],"iat":1709290800,"exp":1709294400,"scope":"read"}`),
+			want:  "JWT alg=RS256 kid=key-1 iss=https://auth.example.com sub=svc-billing aud=api,billing iat=2024-03-01T11:00:00Z (issued 1h3m ago) exp=2024-03-01T12:00:00Z (expired 3m ago) signature=████████████████████",
+			ok:    true,
+		},
+		{
+			desc:  "expires",
+			token: testJWT(`{"alg":"ES256"}`, `{"sub":"jane doe","aud":"api","exp":1709467200}`),
+			want:  `JWT alg=ES256 sub="jane doe" aud=api exp=2024-03-03T12:00:00Z (expires in 1d) signature=████████████████████`,
+			ok:    true,
+		},
+		{
+			desc:  "unsecured",
+			token: "eyJhbGciOiJub25lIn0.eyJzdWIiOiIxIn0.",
+			want:  "JWT alg=none sub=1",
+			ok:    true,
+		},
+		{
+			desc:  "not a JWT",
+			token: "mF_9.B5f-4.1JqM",
+		},
+		{
+			desc:  "missing alg",
+			token: testJWT(`{"typ":"JWT"}`, `{"sub":"1"}`),
+		},
+		{
+			desc:  "opaque token",
+			token: "2YotnFZFEjr1zCsicMWpAA",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, ok := JWT(tc.token, now, Block)
+			if ok != tc.ok || got != tc.want {
+				t.Errorf("got JWT(%q) = (%q, %v), wanted (%q, %v)", tc.token, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestRedactJWTSignature(t *testing.T) {
+	if got, want := RedactJWTSignature("a.b.c", Block), "a.b.████████████████████"; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if got, want := RedactJWTSignature("a.b.", Block), "a.b."; got != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,169 @@
+package header
+
+import (
+	"math"
+	"net/http"
+	"strings"
+)
+
+// SanitizeSecrets redacts the values of headers that look like secrets, noting which rule detected them.
+// Headers with a sanitizer are skipped, as they are sanitized by Sanitize already.
+func SanitizeSecrets(sanitizers map[string]SanitizeHeaderFunc, headers http.Header, redact Redactor) http.Header {
+	var redacted = http.Header{}
+
+	for k, values := range headers {
+		if _, ok := sanitizers[http.CanonicalHeaderKey(k)]; ok {
+			redacted[k] = values
+			continue
+		}
+
+		var list = []string{}
+		for _, v := range values {
+			if rule, ok := DetectSecret(k, v); ok {
+				v = redact(v) + " (secret detected: " + rule + ")"
+			}
+			list = append(list, v)
+		}
+		redacted[k] = list
+	}
+
+	return redacted
+}
+
+// notSecretHeaders are standard headers that aren't credentials, such as Content-Type,
+// whose media type parameters (e.g., multipart boundaries) might look random,
+// or headers carrying values that look random, but aren't credentials.
+var notSecretHeaders = map[string]struct{}{
+	"Accept":               {},
+	"Accept-Encoding":      {},
+	"Accept-Language":      {},
+	"Accept-Ranges":        {},
+	"Age":                  {},
+	"Cache-Control":        {},
+	"Connection":           {},
+	"Content-Digest":       {},
+	"Content-Disposition":  {},
+	"Content-Encoding":     {},
+	"Content-Language":     {},
+	"Content-Length":       {},
+	"Content-Md5":          {},
+	"Content-Range":        {},
+	"Content-Type":         {},
+	"Date":                 {},
+	"Digest":               {},
+	"Etag":                 {},
+	"Expires":              {},
+	"Idempotency-Key":      {},
+	"If-Match":             {},
+	"If-Modified-Since":    {},
+	"If-None-Match":        {},
+	"Last-Modified":        {},
+	"Range":                {},
+	"Repr-Digest":          {},
+	"Sec-Websocket-Accept": {},
+	"Sec-Websocket-Key":    {},
+	"Server":               {},
+	"Traceparent":          {},
+	"Tracestate":           {},
+	"Transfer-Encoding":    {},
+	"User-Agent":           {},
+	"Vary":                 {},
+	"Via":                  {},
+	"X-Correlation-Id":     {},
+	"X-Request-Id":         {},
+}
+
+// secretNameSuffixes of header names, such as X-Auth-Token.
+var secretNameSuffixes = []string{"-Token", "-Key", "-Secret"}
+
+// secretPrefixes of well-known credential formats.
+var secretPrefixes = []struct {
+	prefix, name string
+}{
+	{"ghp_", "GitHub token"},
+	{"gho_", "GitHub token"},
+	{"ghu_", "GitHub token"},
+	{"ghs_", "GitHub token"},
+	{"ghr_", "GitHub token"},
+	{"github_pat_", "GitHub token"},
+	{"glpat-", "GitLab token"},
+	{"sk_live_", "Stripe key"},
+	{"rk_live_", "Stripe key"},
+	{"AKIA", "AWS access key"},
+	{"ASIA", "AWS access key"},
+	{"xoxb-", "Slack token"},
+	{"xoxp-", "Slack token"},
+	{"xoxa-", "Slack token"},
+	{"xoxs-", "Slack token"},
+	{"AIza", "Google API key"},
+}
+
+// DetectSecret checks if a header looks like it carries a secret, from its name or value,
+// returning a description of the rule that detected it.
+func DetectSecret(name, value string) (rule string, ok bool) {
+	name = http.CanonicalHeaderKey(name)
+	if _, ok := notSecretHeaders[name]; ok || value == "" {
+		return "", false
+	}
+	for _, suffix := range secretNameSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return "name matches *" + suffix, true
+		}
+	}
+	if strings.Contains(name, "Signature") {
+		return "name matches *Signature*", true
+	}
+	for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == ',' || r == ';' || r == '"'
+	}) {
+		// check the values of key=value pairs, but keep base64 padding.
+		if k, v, ok := strings.Cut(word, "="); ok && k != "" && strings.TrimRight(v, "=") != "" {
+			word = v
+		}
+		for _, p := range secretPrefixes {
+			if strings.HasPrefix(word, p.prefix) && len(word) >= len(p.prefix)+16 {
+				return "value matches " + p.name + " prefix " + p.prefix, true
+			}
+		}
+		if highEntropy(word) {
+			return "value has high entropy", true
+		}
+	}
+	return "", false
+}
+
+// highEntropy checks if a word looks like a random base64 or base64url encoded string,
+// mixing uppercase and lowercase letters, and digits. Hexadecimal strings, such as UUIDs and hashes, are ignored.
+func highEntropy(word string) bool {
+	word = strings.TrimRight(word, "=")
+	if len(word) < 32 {
+		return false
+	}
+	var (
+		upper, lower, digit bool
+		counts              = map[rune]int{}
+	)
+	for _, r := range word {
+		switch {
+		case 'A' <= r && r <= 'Z':
+			upper = true
+		case 'a' <= r && r <= 'z':
+			lower = true
+		case '0' <= r && r <= '9':
+			digit = true
+		case r == '+' || r == '/' || r == '-' || r == '_':
+		default:
+			return false
+		}
+		counts[r]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(len(word))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 4.3
+}
@@ -0,0 +1,88 @@
+package header
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultQueryParams are the query parameters carrying signatures and credentials of presigned URLs,
+// such as those of Amazon S3, Google Cloud Storage, Amazon CloudFront, and Azure Storage.
+var DefaultQueryParams = []string{
+	"X-Amz-Credential",
+	"X-Amz-Security-Token",
+	"X-Amz-Signature",
+	"X-Goog-Credential",
+	"X-Goog-Signature",
+	"Signature",
+	"sig",
+}
+
+// URLSanitizer returns a sanitizer for headers containing URLs, such as Location and Referer.
+// See SanitizeURL. Values that cannot be parsed are redacted.
+func URLSanitizer(params []string, showUsername bool, redact Redactor) SanitizeHeaderFunc {
+	return func(unsafe string) string {
+		u, err := url.Parse(unsafe)
+		if err != nil {
+			return redact(unsafe)
+		}
+		return SanitizeURL(u, params, showUsername, redact)
+	}
+}
+
+// SanitizeURL returns the URL with its userinfo and the values of the given query parameters redacted.
+// Query parameters are matched case-insensitively. If showUsername is set, the username is kept,
+// so you can tell which account was used.
+func SanitizeURL(u *url.URL, params []string, showUsername bool, redact Redactor) string {
+	redacted := *u
+	redacted.RawQuery = RedactQuery(u.RawQuery, params, redact)
+	redacted.User = nil
+	s := redacted.String()
+	if u.User == nil {
+		return s
+	}
+	var userinfo string
+	switch password, ok := u.User.Password(); {
+	case !showUsername:
+		userinfo = redact(u.User.String())
+	case ok:
+		userinfo = url.User(u.User.Username()).String() + ":" + redact(password)
+	default:
+		userinfo = url.User(u.User.Username()).String()
+	}
+	// insert the userinfo directly, as url.URL would percent-encode the redaction block.
+	i := strings.Index(s, "//")
+	if i == -1 {
+		return s
+	}
+	return s[:i+2] + userinfo + "@" + s[i+2:]
+}
+
+// RedactQuery redacts the values of the given parameters of a query string, keeping its order and encoding.
+func RedactQuery(rawQuery string, params []string, redact Redactor) string {
+	if rawQuery == "" || len(params) == 0 {
+		return rawQuery
+	}
+	pairs := strings.Split(rawQuery, "&")
+	for i, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		name, err := url.QueryUnescape(k)
+		if err != nil {
+			name = k
+		}
+		for _, param := range params {
+			if strings.EqualFold(name, param) {
+				// redact the unescaped value, so the same value is redacted the same way regardless of its encoding.
+				value, err := url.QueryUnescape(v)
+				if err != nil {
+					value = v
+				}
+				pairs[i] = k + "=" + redact(value)
+				break
+			}
+		}
+	}
+	return strings.Join(pairs, "&")
+}
@@ -0,0 +1,184 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// JSONRPCFormatter formats JSON-RPC 2.0 requests and responses, including batches.
+//
+// Calls are printed with their method and id, and errors with their code and message.
+// When the request body is printed, the responses of a batch are paired with their calls by id.
+// Other JSON documents are indented like JSONFormatter does.
+//
+// The method of requests, and the errors of responses, are used as their summary.
+type JSONRPCFormatter struct{}
+
+// Match JSON media types.
+func (j *JSONRPCFormatter) Match(mediatype string) bool {
+	return jsonTypeRE.MatchString(mediatype) || mediatype == "application/json-rpc"
+}
+
+// Format JSON-RPC content.
+func (j *JSONRPCFormatter) Format(w io.Writer, src []byte) error {
+	return j.FormatResponse(w, nil, src)
+}
+
+// FormatResponse formats JSON-RPC content, pairing responses with the calls of the request.
+func (j *JSONRPCFormatter) FormatResponse(w io.Writer, request, src []byte) error {
+	msgs, batch, ok := parseJSONRPC(src)
+	if !ok {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, src, "", "    "); err != nil {
+			return err
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	calls := map[string]jsonRPCMessage{}
+	if reqs, _, ok := parseJSONRPC(request); ok {
+		for _, r := range reqs {
+			if r.Method != "" && r.ID != nil {
+				calls[string(r.ID)] = r
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if batch {
+		fmt.Fprintf(&buf, "batch of %d\n", len(msgs))
+	}
+	responded := map[string]struct{}{}
+	for _, m := range msgs {
+		call, paired := calls[string(m.ID)]
+		if m.ID != nil {
+			responded[string(m.ID)] = struct{}{}
+		}
+		switch {
+		case m.Method != "" && m.ID == nil:
+			fmt.Fprintf(&buf, "notification %s\n", m.Method)
+		case m.Method != "":
+			fmt.Fprintf(&buf, "call %s id=%s\n", m.Method, m.ID)
+		case m.Error != nil:
+			fmt.Fprintf(&buf, "error id=%s", m.ID)
+			if paired {
+				fmt.Fprintf(&buf, " (%s)", call.Method)
+			}
+			fmt.Fprintf(&buf, ": %d %s\n", m.Error.Code, m.Error.Message)
+		default:
+			fmt.Fprintf(&buf, "result id=%s", m.ID)
+			if paired {
+				fmt.Fprintf(&buf, " (%s)", call.Method)
+			}
+			buf.WriteByte('\n')
+		}
+		switch {
+		case m.Method != "":
+			writeJSONRPCValue(&buf, m.Params)
+		case m.Error != nil:
+			writeJSONRPCValue(&buf, m.Error.Data)
+		default:
+			writeJSONRPCValue(&buf, m.Result)
+		}
+	}
+	if len(msgs) != 0 && msgs[0].Method == "" {
+		var missing []string
+		for id, call := range calls {
+			if _, ok := responded[id]; !ok {
+				missing = append(missing, fmt.Sprintf("id=%s (%s)", id, call.Method))
+			}
+		}
+		if len(missing) != 0 {
+			sort.Strings(missing)
+			fmt.Fprintf(&buf, "no response for %s\n", strings.Join(missing, ", "))
+		}
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// Summarize JSON-RPC requests with their methods, and responses with their errors.
+func (j *JSONRPCFormatter) Summarize(mediatype string, src []byte) string {
+	msgs, batch, ok := parseJSONRPC(src)
+	if !ok {
+		return ""
+	}
+	var (
+		methods []string
+		errs    []jsonRPCMessage
+	)
+	for _, m := range msgs {
+		if m.Method != "" {
+			methods = append(methods, m.Method)
+		}
+		if m.Error != nil {
+			errs = append(errs, m)
+		}
+	}
+	switch {
+	case len(methods) != 0 && batch:
+		return fmt.Sprintf("JSON-RPC batch: %s", strings.Join(methods, ", "))
+	case len(methods) != 0 && msgs[0].ID == nil:
+		return fmt.Sprintf("JSON-RPC notification %s", methods[0])
+	case len(methods) != 0:
+		return fmt.Sprintf("JSON-RPC call %s id=%s", methods[0], msgs[0].ID)
+	case len(errs) == 1 && !batch:
+		return fmt.Sprintf("JSON-RPC error %d %s", errs[0].Error.Code, errs[0].Error.Message)
+	case len(errs) != 0:
+		return fmt.Sprintf("JSON-RPC batch: %d results, %d errors", len(msgs)-len(errs), len(errs))
+	}
+	return ""
+}
+
+type jsonRPCMessage struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *jsonRPCError   `json:"error"`
+}
+
+type jsonRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// parseJSONRPC decodes a JSON-RPC 2.0 message or batch.
+func parseJSONRPC(src []byte) (msgs []jsonRPCMessage, batch bool, ok bool) {
+	src = bytes.TrimSpace(src)
+	if len(src) == 0 {
+		return nil, false, false
+	}
+	if batch = src[0] == '['; batch {
+		if err := json.Unmarshal(src, &msgs); err != nil || len(msgs) == 0 {
+			return nil, false, false
+		}
+	} else {
+		var m jsonRPCMessage
+		if err := json.Unmarshal(src, &m); err != nil {
+			return nil, false, false
+		}
+		msgs = []jsonRPCMessage{m}
+	}
+	for _, m := range msgs {
+		if m.Version != "2.0" || (m.Method == "" && m.Result == nil && m.Error == nil) {
+			return nil, false, false
+		}
+	}
+	return msgs, batch, true
+}
+
+func writeJSONRPCValue(buf *bytes.Buffer, v json.RawMessage) {
+	if len(v) == 0 {
+		return
+	}
+	if err := json.Indent(buf, v, "", "    "); err != nil {
+		buf.Write(v)
+	}
+	buf.WriteByte('\n')
+}
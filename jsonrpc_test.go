@@ -0,0 +1,70 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONRPCFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc    string
+		request string
+		src     string
+		want    string
+		summary string
+	}{
+		{
+			desc:    "call",
+			src:     `{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x407d73d8a49eeb85d32cf465507dd71d507100c1","latest"],"id":1}`,
+			want:    "call eth_getBalance id=1\n[\n    \"0x407d73d8a49eeb85d32cf465507dd71d507100c1\",\n    \"latest\"\n]",
+			summary: "JSON-RPC call eth_getBalance id=1",
+		},
+		{
+			desc:    "notification",
+			src:     `{"jsonrpc":"2.0","method":"textDocument/didOpen"}`,
+			want:    "notification textDocument/didOpen",
+			summary: "JSON-RPC notification textDocument/didOpen",
+		},
+		{
+			desc:    "error",
+			src:     `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found","data":"foo"},"id":"a"}`,
+			want:    "error id=\"a\": -32601 Method not found\n\"foo\"",
+			summary: "JSON-RPC error -32601 Method not found",
+		},
+		{
+			desc:    "batch",
+			request: `[{"jsonrpc":"2.0","method":"sum","params":[1,2],"id":1},{"jsonrpc":"2.0","method":"notify"},{"jsonrpc":"2.0","method":"foo","id":2},{"jsonrpc":"2.0","method":"bar","id":3}]`,
+			src:     `[{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":2},{"jsonrpc":"2.0","result":3,"id":1}]`,
+			want: `batch of 2
+error id=2 (foo): -32601 Method not found
+result id=1 (sum)
+3
+no response for id=3 (bar)`,
+			summary: "JSON-RPC batch: 1 results, 1 errors",
+		},
+		{
+			desc: "JSON",
+			src:  `{"jsonrpc":"1.0","method":"x"}`,
+			want: "{\n    \"jsonrpc\": \"1.0\",\n    \"method\": \"x\"\n}",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			f := &JSONRPCFormatter{}
+			if !f.Match("application/json") {
+				t.Error("expected JSONRPCFormatter to match application/json")
+			}
+			var buf bytes.Buffer
+			if err := f.FormatResponse(&buf, []byte(tc.request), []byte(tc.src)); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %q, wanted %q", got, tc.want)
+			}
+			if got := f.Summarize("application/json", []byte(tc.src)); got != tc.summary {
+				t.Errorf("got summary %q, wanted %q", got, tc.summary)
+			}
+		})
+	}
+}
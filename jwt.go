@@ -0,0 +1,39 @@
+package httpretty
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/henvic/httpretty/internal/color"
+	"github.com/henvic/httpretty/internal/header"
+)
+
+// jwtBodyFields are the members of OAuth 2.0 and OpenID Connect token responses that might carry a JWT.
+var jwtBodyFields = []string{"access_token", "id_token"}
+
+// decodeJWTFields prints the decoded JWTs found in the access_token and id_token members of a JSON object,
+// returning the body with their signatures redacted. Tokens aren't decoded if sanitization is skipped.
+func (p *printer) decodeJWTFields(body []byte) []byte {
+	if p.logger.SkipSanitize {
+		return body
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return body
+	}
+	for _, name := range jwtBodyFields {
+		var token string
+		if err := json.Unmarshal(members[name], &token); err != nil {
+			continue
+		}
+		decoded, ok := header.JWT(token, time.Now(), p.logger.getRedactor())
+		if !ok {
+			continue
+		}
+		p.printf("* %s: %s\n", name, p.format(color.FgBlue, p.escapeLine(p.scrubPII(decoded))))
+		// base64url encoded tokens are never escaped in JSON strings.
+		body = []byte(strings.ReplaceAll(string(body), token, header.RedactJWTSignature(token, p.logger.getRedactor())))
+	}
+	return body
+}
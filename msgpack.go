@@ -0,0 +1,221 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// MessagePackFormatter formats MessagePack documents as a JSON-like tree.
+//
+// Values are indented like JSONFormatter does. Binary data, extension types, and map keys
+// that aren't strings are annotated with their type. Timestamps (extension type -1) are
+// printed in RFC 3339 format.
+type MessagePackFormatter struct{}
+
+// Match MessagePack media types.
+func (m *MessagePackFormatter) Match(mediatype string) bool {
+	switch mediatype {
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return true
+	}
+	return strings.HasSuffix(mediatype, "+msgpack")
+}
+
+// AcceptsBinary data.
+func (m *MessagePackFormatter) AcceptsBinary() bool {
+	return true
+}
+
+// Format MessagePack content.
+func (m *MessagePackFormatter) Format(w io.Writer, src []byte) error {
+	var nodes []treeNode
+	for len(src) != 0 {
+		d := msgpackDecoder{b: src}
+		n, err := d.decode(0)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+		src = d.b
+	}
+	var buf bytes.Buffer
+	writeTrees(&buf, nodes)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of data")
+
+type msgpackDecoder struct {
+	b []byte
+}
+
+func (d *msgpackDecoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)) {
+		return nil, errMsgpackTruncated
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v, nil
+}
+
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+func (d *msgpackDecoder) decode(depth int) (treeNode, error) {
+	if depth > treeMaxDepth {
+		return treeNode{}, errors.New("msgpack: exceeded maximum nesting depth")
+	}
+	b, err := d.read(1)
+	if err != nil {
+		return treeNode{}, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return treeUint(uint64(c)), nil
+	case c >= 0xe0:
+		return treeInt(int64(int8(c))), nil
+	case c&0xf0 == 0x80:
+		return d.collection(treeMap, uint64(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.collection(treeArray, uint64(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.str(uint64(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return treeNull(), nil
+	case 0xc2, 0xc3:
+		return treeBool(c == 0xc3), nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return treeNode{}, err
+		}
+		v, err := d.read(n)
+		if err != nil {
+			return treeNode{}, err
+		}
+		return treeBinary(v, "binary"), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return treeNode{}, err
+		}
+		return d.ext(n)
+	case 0xca:
+		v, err := d.uint(4)
+		return treeFloat(float64(math.Float32frombits(uint32(v))), 32), err
+	case 0xcb:
+		v, err := d.uint(8)
+		return treeFloat(math.Float64frombits(v), 64), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (c - 0xcc))
+		return treeUint(v), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := d.uint(size)
+		// sign extend
+		shift := 64 - 8*size
+		return treeInt(int64(v<<shift) >> shift), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return treeNode{}, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return treeNode{}, err
+		}
+		return d.collection(treeArray, n, depth)
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return treeNode{}, err
+		}
+		return d.collection(treeMap, n, depth)
+	}
+	return treeNode{}, fmt.Errorf("msgpack: invalid type 0x%02x", c)
+}
+
+func (d *msgpackDecoder) str(n uint64) (treeNode, error) {
+	v, err := d.read(n)
+	if err != nil {
+		return treeNode{}, err
+	}
+	return treeString(string(v)), nil
+}
+
+func (d *msgpackDecoder) collection(kind treeKind, n uint64, depth int) (treeNode, error) {
+	if kind == treeMap {
+		n *= 2
+	}
+	// each element takes at least a byte, so don't trust the length for allocating.
+	if n > uint64(len(d.b)) {
+		return treeNode{}, errMsgpackTruncated
+	}
+	node := treeNode{kind: kind, items: make([]treeNode, 0, n)}
+	for i := uint64(0); i < n; i++ {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return treeNode{}, err
+		}
+		node.items = append(node.items, item)
+	}
+	return node, nil
+}
+
+func (d *msgpackDecoder) ext(n uint64) (treeNode, error) {
+	typ, err := d.read(1)
+	if err != nil {
+		return treeNode{}, err
+	}
+	data, err := d.read(n)
+	if err != nil {
+		return treeNode{}, err
+	}
+	if t := int8(typ[0]); t == -1 {
+		if ts, ok := msgpackTimestamp(data); ok {
+			return treeNode{value: `"` + treeTime(ts) + `"`, typ: "timestamp", note: "timestamp"}, nil
+		}
+	}
+	return treeBinary(data, fmt.Sprintf("ext type %d", int8(typ[0]))), nil
+}
+
+// msgpackTimestamp decodes the timestamp extension type.
+func msgpackTimestamp(b []byte) (time.Time, bool) {
+	switch len(b) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), true
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)), true
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,66 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMessagePackFormatter(t *testing.T) {
+	t.Parallel()
+	src := []byte{
+		0x87,                                                         // map of 7
+		0xa4, 'n', 'a', 'm', 'e', 0xa6, 'g', 'o', 'p', 'h', 'e', 'r', // "name": "gopher"
+		0xa3, 'a', 'g', 'e', 0xd0, 0xfe, // "age": -2
+		0xa4, 't', 'a', 'g', 's', 0x92, 0xc3, 0xc0, // "tags": [true, nil]
+		0xa3, 'b', 'i', 'n', 0xc4, 0x02, 0xde, 0xad, // "bin": bin8
+		0x01, 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0, // 1: 1.5
+		0xa2, 't', 's', 0xd6, 0xff, 0x65, 0x53, 0xf1, 0x00, // "ts": timestamp 32
+		0xa3, 'e', 'x', 't', 0xd4, 0x05, 0x2a, // "ext": fixext1 type 5
+	}
+	f := &MessagePackFormatter{}
+	if !f.Match("application/msgpack") || !f.Match("application/vnd.example+msgpack") || !f.AcceptsBinary() {
+		t.Error("expected MessagePackFormatter to match MessagePack binary data")
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, src); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `{
+    "name": "gopher",
+    "age": -2,
+    "tags": [
+        true,
+        null
+    ],
+    "bin": 0xdead (binary, 2 bytes),
+    1 (integer key): 1.5,
+    "ts": "2023-11-14T22:13:20Z" (timestamp),
+    "ext": 0x2a (ext type 5, 1 byte)
+}`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+}
+
+func TestMessagePackFormatterInvalid(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		src  []byte
+		want string
+	}{
+		{"truncated", []byte{0xa4, 'a'}, "msgpack: unexpected end of data"},
+		{"type", []byte{0xc1}, "msgpack: invalid type 0xc1"},
+		{"length", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}, "msgpack: unexpected end of data"},
+		{"depth", bytes.Repeat([]byte{0x91}, 100), "msgpack: exceeded maximum nesting depth"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := (&MessagePackFormatter{}).Format(&buf, tc.src); err == nil || err.Error() != tc.want {
+				t.Errorf("got format error = %v, wanted %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NDJSONFormatter formats newline-delimited JSON (NDJSON) and JSON Lines documents.
+//
+// Each line is printed as its own indented JSON document, preceded by its record index.
+// Invalid records are reported with their line number, and the remaining records are still printed.
+// It is a StreamFormatter, so streaming endpoints can be logged record by record (see Logger.StreamResponseBody).
+type NDJSONFormatter struct{}
+
+var ndjsonMediatypes = map[string]struct{}{
+	"application/x-ndjson":    {},
+	"application/ndjson":      {},
+	"application/jsonl":       {},
+	"application/x-jsonl":     {},
+	"application/jsonlines":   {},
+	"application/x-jsonlines": {},
+}
+
+// Match NDJSON and JSON Lines media types.
+func (n *NDJSONFormatter) Match(mediatype string) bool {
+	_, ok := ndjsonMediatypes[mediatype]
+	return ok
+}
+
+// Format NDJSON content.
+func (n *NDJSONFormatter) Format(w io.Writer, src []byte) error {
+	var buf bytes.Buffer
+	s := n.NewStream(&buf)
+	if _, err := s.Write(src); err != nil {
+		return err
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+	// the body is printed with a trailing newline already.
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// NewStream returns a writer that formats each record as soon as its line is complete.
+func (n *NDJSONFormatter) NewStream(w io.Writer) io.WriteCloser {
+	return &ndjsonStream{w: w}
+}
+
+// newLimitedStream returns a stream skipping lines longer than max bytes, rather than buffering them.
+func (n *NDJSONFormatter) newLimitedStream(w io.Writer, max int64) io.WriteCloser {
+	return &ndjsonStream{w: w, max: max}
+}
+
+type ndjsonStream struct {
+	w        io.Writer
+	buf      []byte // incomplete line
+	max      int64  // maximum length of the incomplete line, if set
+	skipping bool   // the rest of the current line is skipped
+	line     int
+	record   int
+}
+
+func (s *ndjsonStream) Write(p []byte) (int, error) {
+	n := len(p)
+	if s.skipping {
+		i := bytes.IndexByte(p, '\n')
+		if i == -1 {
+			return n, nil
+		}
+		s.skipping = false
+		p = p[i+1:]
+	}
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i == -1 {
+			break
+		}
+		line := s.buf[:i]
+		s.buf = s.buf[i+1:]
+		var err error
+		if s.max > 0 && int64(len(line)) > s.max {
+			err = s.skipLine()
+		} else {
+			err = s.formatLine(line)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if s.max > 0 && int64(len(s.buf)) > s.max {
+		// drop the incomplete line, and skip the rest of it.
+		s.buf = nil
+		s.skipping = true
+		if err := s.skipLine(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (s *ndjsonStream) skipLine() error {
+	s.line++
+	s.record++
+	_, err := fmt.Fprintf(s.w, "#%d is too long, skipping (longer than %d bytes)\n", s.record-1, s.max)
+	return err
+}
+
+// Close formats the last line, if it isn't terminated by a newline.
+func (s *ndjsonStream) Close() error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	err := s.formatLine(s.buf)
+	s.buf = nil
+	return err
+}
+
+func (s *ndjsonStream) formatLine(line []byte) error {
+	s.line++
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "#%d\n", s.record)
+	s.record++
+	if err := json.Indent(&buf, line, "", "    "); err != nil {
+		buf.Truncate(0)
+		fmt.Fprintf(&buf, "#%d invalid JSON on line %d: %v\n%s", s.record-1, s.line, err, line)
+	}
+	buf.WriteByte('\n')
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
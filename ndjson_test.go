@@ -0,0 +1,99 @@
+package httpretty
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestNDJSONFormatter(t *testing.T) {
+	t.Parallel()
+	f := &NDJSONFormatter{}
+	for _, mediatype := range []string{"application/x-ndjson", "application/jsonl"} {
+		if !f.Match(mediatype) {
+			t.Errorf("expected NDJSONFormatter to match %q", mediatype)
+		}
+	}
+	if f.Match("application/json") {
+		t.Error("NDJSONFormatter shouldn't match application/json")
+	}
+	var buf bytes.Buffer
+	src := "{\"id\":1}\n\n{\"id\": }\n[1,2]"
+	if err := f.Format(&buf, []byte(src)); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `#0
+{
+    "id": 1
+}
+#1 invalid JSON on line 3: invalid character '}' looking for beginning of value
+{"id": }
+#2
+[
+    1,
+    2
+]`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+}
+
+func TestNDJSONFormatterStream(t *testing.T) {
+	t.Parallel()
+	var records []string
+	s := (&NDJSONFormatter{}).NewStream(writerFunc(func(p []byte) (int, error) {
+		records = append(records, string(p))
+		return len(p), nil
+	}))
+	for _, chunk := range []string{`{"a"`, ":1}\n{\"b\":", "2}\n", `{"c":3}`} {
+		if _, err := s.Write([]byte(chunk)); err != nil {
+			t.Errorf("got stream error = %v, wanted nil", err)
+		}
+	}
+	if len(records) != 2 {
+		t.Errorf("got %d records before closing the stream, wanted 2", len(records))
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("got stream error = %v, wanted nil", err)
+	}
+	want := []string{
+		"#0\n{\n    \"a\": 1\n}\n",
+		"#1\n{\n    \"b\": 2\n}\n",
+		"#2\n{\n    \"c\": 3\n}\n",
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("got records %q, wanted %q", records, want)
+	}
+}
+
+func TestNDJSONFormatterLimitedStream(t *testing.T) {
+	t.Parallel()
+	var records []string
+	s := (&NDJSONFormatter{}).newLimitedStream(writerFunc(func(p []byte) (int, error) {
+		records = append(records, string(p))
+		return len(p), nil
+	}), 10)
+	for _, chunk := range []string{`{"a":1}`, "\n{\"long\":", `"aaaaaaaaaa`, `aaaaaaaaaa`, "aaa\"}\n{", "\"c\":3}\n[1,2,3,4,5,6]\n"} {
+		if _, err := s.Write([]byte(chunk)); err != nil {
+			t.Errorf("got stream error = %v, wanted nil", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("got stream error = %v, wanted nil", err)
+	}
+	want := []string{
+		"#0\n{\n    \"a\": 1\n}\n",
+		"#1 is too long, skipping (longer than 10 bytes)\n",
+		"#2\n{\n    \"c\": 3\n}\n",
+		"#3 is too long, skipping (longer than 10 bytes)\n",
+	}
+	if fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("got records %q, wanted %q", records, want)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
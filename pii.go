@@ -0,0 +1,314 @@
+package httpretty
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/henvic/httpretty/internal/header"
+)
+
+// PII is a set of types of personally identifiable information to redact from header values and bodies.
+// See Logger.RedactPII.
+type PII int
+
+// Types of personally identifiable information that can be detected.
+const (
+	// PIIEmail detects email addresses.
+	PIIEmail PII = 1 << iota
+
+	// PIICardNumber detects payment card numbers, validated with the Luhn algorithm.
+	PIICardNumber
+
+	// PIIIBAN detects International Bank Account Numbers, validated with their check digits.
+	PIIIBAN
+
+	// PIIIPAddress detects IPv4 and IPv6 addresses.
+	PIIIPAddress
+
+	// PIIPhoneNumber detects phone numbers, either in the international format (+14155552671)
+	// or with separators ((415) 555-2671 or 415-555-2671).
+	PIIPhoneNumber
+
+	// PIIAll detects all types of personally identifiable information.
+	PIIAll = PIIEmail | PIICardNumber | PIIIBAN | PIIIPAddress | PIIPhoneNumber
+)
+
+// piiDetector finds a type of personally identifiable information.
+type piiDetector struct {
+	pii              PII
+	singular, plural string
+	re               *regexp.Regexp
+	valid            func(match string) bool
+}
+
+// piiDetectors are applied in order, so numbers that are part of a card number or IP address
+// aren't detected as phone numbers.
+var piiDetectors = []piiDetector{
+	{
+		pii:      PIIEmail,
+		singular: "email",
+		plural:   "emails",
+		re:       regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+	},
+	{
+		pii:      PIIIBAN,
+		singular: "IBAN",
+		plural:   "IBANs",
+		re:       regexp.MustCompile(`[A-Z]{2}[0-9]{2}(?: ?[A-Z0-9]){11,30}`),
+		valid:    validIBAN,
+	},
+	{
+		pii:      PIICardNumber,
+		singular: "card number",
+		plural:   "card numbers",
+		re:       regexp.MustCompile(`[0-9](?:[ -]?[0-9]){12,18}`),
+		valid:    validLuhn,
+	},
+	{
+		pii:      PIIIPAddress,
+		singular: "IP address",
+		plural:   "IP addresses",
+		re:       regexp.MustCompile(`(?:[0-9]{1,3}\.){3}[0-9]{1,3}|[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}`),
+		valid:    validIP,
+	},
+	{
+		pii:      PIIPhoneNumber,
+		singular: "phone number",
+		plural:   "phone numbers",
+		re:       regexp.MustCompile(`\+[0-9]{8,15}|(?:\+[0-9]{1,3}[ .-]?)?(?:\([0-9]{2,4}\) ?|[0-9]{2,4}[ .-])[0-9]{3,4}[ .-][0-9]{3,4}`),
+		valid:    validPhoneNumber,
+	},
+}
+
+// scrubPII redacts the enabled types of personally identifiable information, recording the type of what was redacted.
+func scrubPII(s string, enabled PII, found map[string]PII, redact header.Redactor) string {
+	for _, d := range piiDetectors {
+		if enabled&d.pii == 0 {
+			continue
+		}
+		s = replaceAllIndex(s, d.re, func(match string) (string, bool) {
+			if d.valid != nil && !d.valid(match) {
+				return match, false
+			}
+			found[match] = d.pii
+			return redact(match), true
+		})
+	}
+	return s
+}
+
+// replaceAllIndex replaces the matches of the regular expression that aren't part of a larger word or number,
+// as Go regular expressions don't support lookarounds.
+func replaceAllIndex(s string, re *regexp.Regexp, replace func(match string) (string, bool)) string {
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		start, end := loc[0], loc[1]
+		// the m ending an escape sequence setting colors isn't part of a word.
+		if start > 0 && isWordByte(s[start-1]) && !sgrSuffixRE.MatchString(s[:start]) || end < len(s) && isWordByte(s[end]) {
+			continue
+		}
+		// a dot can end a sentence, but not be followed by a digit, as in a version number.
+		if start > 0 && s[start-1] == '.' || end+1 < len(s) && s[end] == '.' && isDigit(s[end+1]) {
+			continue
+		}
+		replaced, ok := replace(s[start:end])
+		if !ok {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(replaced)
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// sgrSuffixRE matches an escape sequence setting colors at the end of a string.
+var sgrSuffixRE = regexp.MustCompile(`\x1b\[[0-9;]*m$`)
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isWordByte(c byte) bool {
+	return isDigit(c) || isASCIILetter(c) || c == '_'
+}
+
+// validLuhn checks the Luhn checksum of card numbers.
+func validLuhn(match string) bool {
+	var digits []int
+	for _, r := range match {
+		if '0' <= r && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	var sum int
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validIBAN checks the mod-97 check digits of IBANs.
+func validIBAN(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if 'A' <= r && r <= 'Z' {
+			fmt.Fprintf(&numeric, "%d", r-'A'+10)
+			continue
+		}
+		numeric.WriteRune(r)
+	}
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && n.Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func validIP(match string) bool {
+	if strings.Count(match, ":") >= 2 && len(match) < 3 {
+		return false
+	}
+	return net.ParseIP(match) != nil
+}
+
+func validPhoneNumber(match string) bool {
+	var digits int
+	for _, r := range match {
+		if '0' <= r && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// piiCounts counts the different values redacted of each type.
+func piiCounts(found map[string]PII) map[PII]int {
+	counts := map[PII]int{}
+	for _, pii := range found {
+		counts[pii]++
+	}
+	return counts
+}
+
+// piiSummary describes what was redacted, such as "3 emails, 1 card number".
+func piiSummary(counts map[PII]int) string {
+	var parts []string
+	for _, d := range piiDetectors {
+		switch n := counts[d.pii]; n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+d.singular)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", n, d.plural))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// scrubPII redacts personally identifiable information if RedactPII is set.
+func (p *printer) scrubPII(s string) string {
+	if p.logger.RedactPII == 0 {
+		return s
+	}
+	root := p
+	for root.parent != nil {
+		root = root.parent
+	}
+	if root.pii == nil {
+		root.pii = map[string]PII{}
+	}
+	return scrubPII(s, p.logger.RedactPII, root.pii, p.logger.getRedactor())
+}
+
+// piiSkipHeaders carry the address the request is sent to, rather than information about its users.
+var piiSkipHeaders = map[string]struct{}{
+	"Host":             {},
+	"X-Forwarded-Host": {},
+}
+
+// scrubHeaderPII redacts personally identifiable information from a header value, unless it carries
+// the address the request is sent to.
+func (p *printer) scrubHeaderPII(key, value string) string {
+	if _, ok := piiSkipHeaders[textproto.CanonicalMIMEHeaderKey(key)]; ok {
+		return value
+	}
+	return p.scrubPII(value)
+}
+
+// scrubQueryPII redacts personally identifiable information from the query values of a URL.
+// Values are unescaped before being scrubbed, so that encoded emails such as bob%40example.com are found,
+// and the redacted ones are inserted as is, as header.RedactQuery does.
+func (p *printer) scrubQueryPII(u *url.URL) *url.URL {
+	if p.logger.RedactPII == 0 || u.RawQuery == "" {
+		return u
+	}
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			value = v
+		}
+		if scrubbed := p.scrubPII(value); scrubbed != value {
+			pairs[i] = k + "=" + scrubbed
+		}
+	}
+	scrubbed := *u
+	scrubbed.RawQuery = strings.Join(pairs, "&")
+	return &scrubbed
+}
+
+// scrubURLPII redacts personally identifiable information from a URL, leaving its host out.
+func (p *printer) scrubURLPII(s string, hasHost bool) string {
+	if p.logger.RedactPII == 0 {
+		return s
+	}
+	i := strings.Index(s, "//")
+	if !hasHost || i == -1 {
+		return p.scrubPII(s)
+	}
+	end := len(s)
+	if j := strings.IndexAny(s[i+2:], "/?#"); j != -1 {
+		end = i + 2 + j
+	}
+	authority := s[i+2 : end]
+	// the userinfo is redacted, unless DecodeBasicAuth is set.
+	if at := strings.LastIndex(authority, "@"); at != -1 {
+		authority = p.scrubPII(authority[:at]) + authority[at:]
+	}
+	return s[:i+2] + authority + p.scrubPII(s[end:])
+}
+
+// printPIISummary prints what personally identifiable information was redacted from the exchange, if any.
+func (p *printer) printPIISummary() {
+	if s := piiSummary(piiCounts(p.pii)); s != "" {
+		p.printf("* redacted %s\n", s)
+	}
+	p.pii = nil
+}
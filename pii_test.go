@@ -0,0 +1,147 @@
+package httpretty
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/henvic/httpretty/internal/header"
+)
+
+func TestScrubPII(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc    string
+		enabled PII
+		src     string
+		want    string
+		counts  map[PII]int
+	}{
+		{
+			desc:    "emails",
+			enabled: PIIAll,
+			src:     `{"email":"jane.doe+news@example.co.uk","cc":["gopher@golang.org"]}`,
+			want:    `{"email":"████████████████████","cc":["████████████████████"]}`,
+			counts:  map[PII]int{PIIEmail: 2},
+		},
+		{
+			desc:    "card numbers",
+			enabled: PIIAll,
+			src:     "card=4111 1111 1111 1111&backup=5500-0000-0000-0004&order=4111111111111112",
+			want:    "card=████████████████████&backup=████████████████████&order=4111111111111112",
+			counts:  map[PII]int{PIICardNumber: 2},
+		},
+		{
+			desc:    "IBANs",
+			enabled: PIIAll,
+			src:     "pay to GB82 WEST 1234 5698 7654 32 or DE89370400440532013000, not DE00370400440532013000",
+			want:    "pay to ████████████████████ or ████████████████████, not DE00370400440532013000",
+			counts:  map[PII]int{PIIIBAN: 2},
+		},
+		{
+			desc:    "IP addresses",
+			enabled: PIIAll,
+			src:     "from 203.0.113.7 and 2001:db8::8a2e:370:7334, version 1.2.3.4.5, not 999.1.1.1 or 12:30:45",
+			want:    "from ████████████████████ and ████████████████████, version 1.2.3.4.5, not 999.1.1.1 or 12:30:45",
+			counts:  map[PII]int{PIIIPAddress: 2},
+		},
+		{
+			desc:    "phone numbers",
+			enabled: PIIAll,
+			src:     "call +14155552671, (415) 555-2671, or +44 20 7946 0958 on 2026-10-18",
+			want:    "call ████████████████████, ████████████████████, or ████████████████████ on 2026-10-18",
+			counts:  map[PII]int{PIIPhoneNumber: 3},
+		},
+		{
+			desc:    "only enabled types",
+			enabled: PIIEmail,
+			src:     "gopher@golang.org 4111111111111111",
+			want:    "████████████████████ 4111111111111111",
+			counts:  map[PII]int{PIIEmail: 1},
+		},
+		{
+			desc:    "words and numbers",
+			enabled: PIIAll,
+			src:     "id=a4111111111111111 n=41111111111111110 user@localhost",
+			want:    "id=a4111111111111111 n=41111111111111110 user@localhost",
+			counts:  map[PII]int{},
+		},
+		{
+			desc:    "colors",
+			enabled: PIIAll,
+			src:     "\x1b[33m4111111111111111\x1b[0m",
+			want:    "\x1b[33m████████████████████\x1b[0m",
+			counts:  map[PII]int{PIICardNumber: 1},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			found := map[string]PII{}
+			if got := scrubPII(tc.src, tc.enabled, found, header.Block); got != tc.want {
+				t.Errorf("got %q, wanted %q", got, tc.want)
+			}
+			if counts := piiCounts(found); !reflect.DeepEqual(counts, tc.counts) {
+				t.Errorf("got counts %v, wanted %v", counts, tc.counts)
+			}
+		})
+	}
+}
+
+func TestScrubURLPII(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc    string
+		url     string
+		hasHost bool
+		want    string
+	}{
+		{"host kept", "http://203.0.113.7:8080/users/bob@example.com", true, "http://203.0.113.7:8080/users/████████████████████"},
+		{"userinfo", "https://bob@example.com@203.0.113.7/?ip=198.51.100.1", true, "https://████████████████████@203.0.113.7/?ip=████████████████████"},
+		{"no path", "http://203.0.113.7", true, "http://203.0.113.7"},
+		{"request URI", "/users?from=203.0.113.7", false, "/users?from=████████████████████"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			logger := &Logger{RedactPII: PIIAll}
+			p := newPrinter(logger)
+			if got := p.scrubURLPII(tc.url, tc.hasHost); got != tc.want {
+				t.Errorf("got %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidLuhn(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]bool{
+		"4111111111111111":     true,
+		"378282246310005":      true,
+		"6011 1111 1111 1117":  true,
+		"4111111111111112":     false,
+		"411111111111":         false,
+		"00000000000000000000": false,
+	}
+	for number, want := range testCases {
+		if got := validLuhn(number); got != want {
+			t.Errorf("got validLuhn(%q) = %v, wanted %v", number, got, want)
+		}
+	}
+}
+
+func TestPIISummary(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		counts map[PII]int
+		want   string
+	}{
+		{nil, ""},
+		{map[PII]int{PIICardNumber: 1, PIIEmail: 3}, "3 emails, 1 card number"},
+		{map[PII]int{PIIIPAddress: 2, PIIPhoneNumber: 1, PIIIBAN: 2}, "2 IBANs, 2 IP addresses, 1 phone number"},
+	}
+	for _, tc := range testCases {
+		if got := piiSummary(tc.counts); got != tc.want {
+			t.Errorf("got piiSummary(%v) = %q, wanted %q", tc.counts, got, tc.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"mime"
 	"net"
 	"net/http"
+	"net/url"
 	"slices"
 	"sort"
 	"strings"
@@ -31,6 +32,78 @@ type printer struct {
 	flusher Flusher
 	logger  *Logger
 	buf     bytes.Buffer
+
+	parent  *printer       // set on printers created by bodyPrinter
+	summary string         // summary of the last body printed, if any
+	body    []byte         // last body printed, if any
+	request []byte         // body of the request, kept for formatting its response
+	pii     map[string]PII // personally identifiable information redacted from the exchange, with its type
+}
+
+// bodyPrinter returns a printer buffering a body section, so that its summary can be printed first.
+func (p *printer) bodyPrinter() *printer {
+	return &printer{
+		flusher: OnEnd,
+		logger:  p.logger,
+		parent:  p,
+	}
+}
+
+// streamPrinter returns the printer a body stream should print to.
+func (p *printer) streamPrinter() *printer {
+	if p.parent != nil {
+		return p.parent
+	}
+	return p
+}
+
+func (p *printer) printSummary(bp *printer) {
+	if bp != nil && bp.summary != "" {
+		p.printf("* %s\n", p.format(color.FgBlue, bp.summary))
+	}
+}
+
+// printResponseSummary prints the summary of the response body or, for gRPC responses, their status.
+func (p *printer) printResponseSummary(bp *printer, h, trailer http.Header) {
+	if bp != nil && bp.summary != "" {
+		p.printSummary(bp)
+		return
+	}
+	p.printHeaderSummary(h, trailer)
+}
+
+// headerSummarizer is implemented by formatters that can describe a response with its header and trailer,
+// such as gRPC responses carrying their status in the trailer.
+type headerSummarizer interface {
+	summarizeHeader(h, trailer http.Header) string
+}
+
+func isHeaderSummarizer(f Formatter) bool {
+	_, ok := f.(headerSummarizer)
+	return ok
+}
+
+// printHeaderSummary prints the summary of a response header and trailer, if its formatter is a headerSummarizer.
+func (p *printer) printHeaderSummary(h, trailer http.Header) {
+	// avoid calling matchers again if no formatter can summarize headers.
+	if !slices.ContainsFunc(p.logger.Formatters, isHeaderSummarizer) {
+		return
+	}
+	mediatype, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	hs, ok := p.findFormatter(mediatype).(headerSummarizer)
+	if !ok {
+		return
+	}
+	if s := hs.summarizeHeader(h, trailer); s != "" {
+		p.printf("* %s\n", p.format(color.FgBlue, p.escapeLine(p.scrubPII(s))))
+	}
+}
+
+func (p *printer) printBody(bp *printer) {
+	if bp != nil {
+		p.print(bp.buf.String())
+		p.maybeOnReady()
+	}
 }
 
 func (p *printer) maybeOnReady() {
@@ -84,26 +157,29 @@ func (p *printer) printf(format string, a ...interface{}) {
 }
 
 func (p *printer) printRequest(req *http.Request) {
+	var body *printer
+	if p.logger.RequestBody && req.Body != nil {
+		body = p.bodyPrinter()
+		body.printRequestBody(req)
+		p.printSummary(body)
+		p.request = body.body
+	}
 	if p.logger.RequestHeader {
 		p.printRequestHeader(req)
 		p.maybeOnReady()
 	}
-	if p.logger.RequestBody && req.Body != nil {
-		p.printRequestBody(req)
-		p.maybeOnReady()
-	}
+	p.printBody(body)
 }
 
 func (p *printer) printRequestInfo(req *http.Request) {
-	to := req.URL.String()
+	to := p.sanitizeURL(req.URL)
 	// req.URL.Host is empty on the request received by a server
 	if req.URL.Host == "" {
-		to = req.Host + to
 		schema := "http://"
 		if req.TLS != nil {
 			schema = "https://"
 		}
-		to = schema + to
+		to = schema + p.escapeLine(req.Host) + to
 	}
 	p.printf("* Request to %s\n", p.format(color.FgBlue, to))
 	if req.RemoteAddr != "" {
@@ -123,7 +199,7 @@ func (p *printer) checkFilter(req *http.Request) (skip bool) {
 	}
 	ok, err := safeFilter(filter, req)
 	if err != nil {
-		p.printf("* cannot filter request: %s: %s\n", p.format(color.FgBlue, fmt.Sprintf("%s %s", req.Method, req.URL)), p.format(color.FgRed, err.Error()))
+		p.printf("* cannot filter request: %s: %s\n", p.format(color.FgBlue, fmt.Sprintf("%s %s", req.Method, p.sanitizeURL(req.URL))), p.format(color.FgRed, err.Error()))
 		return false // never filter out the request if the filter errored
 	}
 	return ok
@@ -144,14 +220,25 @@ func (p *printer) printResponse(resp *http.Response) {
 		p.maybeOnReady()
 		return
 	}
+	var body *printer
+	if p.logger.ResponseBody && resp.Body != nil && (resp.Request == nil || resp.Request.Method != http.MethodHead) {
+		body = p.bodyPrinter()
+		body.request = p.request
+		body.printResponseBodyOut(resp)
+	}
+	if sb, ok := resp.Body.(*streamBody); ok {
+		// the trailer is only set once the streamed body is read.
+		sb.stream.onClose = func() {
+			p.printHeaderSummary(resp.Header, resp.Trailer)
+		}
+	} else {
+		p.printResponseSummary(body, resp.Header, resp.Trailer)
+	}
 	if p.logger.ResponseHeader {
 		p.printResponseHeader(resp.Proto, resp.Status, resp.Header)
 		p.maybeOnReady()
 	}
-	if p.logger.ResponseBody && resp.Body != nil && (resp.Request == nil || resp.Request.Method != http.MethodHead) {
-		p.printResponseBodyOut(resp)
-		p.maybeOnReady()
-	}
+	p.printBody(body)
 }
 
 func (p *printer) checkBodyFiltered(h http.Header) (skip bool, err error) {
@@ -177,17 +264,25 @@ func (p *printer) printResponseBodyOut(resp *http.Response) {
 	if skip {
 		return
 	}
-	if contentType := resp.Header.Get("Content-Type"); contentType != "" && isBinaryMediatype(contentType) {
+	if p.skipBinaryContentType(resp.Header.Get("Content-Type")) {
+		if p.logger.HexDump > 0 {
+			resp.Body = p.printHexDumpHead(resp.Body, resp.ContentLength, p.logger.MaxResponseBody)
+			return
+		}
 		p.println("* body contains binary data")
 		return
 	}
-	if p.logger.MaxResponseBody > 0 && resp.ContentLength > p.logger.MaxResponseBody {
-		p.printf("* body is too long (%d bytes) to print, skipping (longer than %d bytes)\n", resp.ContentLength, p.logger.MaxResponseBody)
+	if stream := p.newBodyStream(resp.Header.Get("Content-Type")); stream != nil {
+		resp.Body = newStreamBody(resp.Body, stream)
+		return
+	}
+	maxBody := p.bodyLimit(resp.Header.Get("Content-Type"), p.logger.MaxResponseBody)
+	if maxBody > 0 && resp.ContentLength > maxBody {
+		p.printf("* body is too long (%d bytes) to print, skipping (longer than %d bytes)\n", resp.ContentLength, maxBody)
 		return
 	}
-	contentType := resp.Header.Get("Content-Type")
 	if resp.ContentLength == -1 {
-		if newBody := p.printBodyUnknownLength(contentType, p.logger.MaxResponseBody, resp.Body); newBody != nil {
+		if newBody := p.printBodyUnknownLength(resp.Header, maxBody, resp.Body); newBody != nil {
 			resp.Body = newBody
 		}
 		return
@@ -198,7 +293,7 @@ func (p *printer) printResponseBodyOut(resp *http.Response) {
 	defer func() {
 		resp.Body = io.NopCloser(&buf)
 	}()
-	p.printBodyReader(contentType, tee)
+	p.printBodyReader(resp.Header, maxBody, tee)
 }
 
 // isBinary uses heuristics to guess if file is binary (actually, "printable" in the terminal).
@@ -263,7 +358,7 @@ func isBinaryMediatype(mediatype string) bool {
 
 const maxDefaultUnknownReadable = 4096 // bytes
 
-func (p *printer) printBodyUnknownLength(contentType string, maxLength int64, r io.ReadCloser) (newBody io.ReadCloser) {
+func (p *printer) printBodyUnknownLength(h http.Header, maxLength int64, r io.ReadCloser) (newBody io.ReadCloser) {
 	if maxLength == 0 {
 		maxLength = maxDefaultUnknownReadable
 	}
@@ -281,7 +376,7 @@ func (p *printer) printBodyUnknownLength(contentType string, maxLength int64, r
 		p.printf("* body is too long, skipping (contains more than %d bytes)\n", n-1)
 	case err == io.ErrUnexpectedEOF || err == nil:
 		// cannot pass same bytes reader below because we only read it once.
-		p.printBodyReader(contentType, bytes.NewReader(pb))
+		p.printBodyReader(h, maxLength, bytes.NewReader(pb))
 	default:
 		p.printf("* cannot read body: %v (%d bytes read)\n", err, n)
 	}
@@ -394,15 +489,99 @@ func (p *printer) printCertificate(hostname string, cert *x509.Certificate) {
 	p.println("*  TLS certificate verify ok.")
 }
 
+// newBodyStream returns a stream printing a body as it is read if StreamResponseBody is set
+// and the formatter matching the media type is a StreamFormatter.
+func (p *printer) newBodyStream(contentType string) *bodyStream {
+	if !p.logger.StreamResponseBody {
+		return nil
+	}
+	mediatype, _, _ := mime.ParseMediaType(contentType)
+	// bodies with redaction rules must be read whole before printing them.
+	if p.logger.getBodyRedaction(mediatype) != nil || p.logger.RedactPII != 0 {
+		return nil
+	}
+	if sf, ok := p.findFormatter(mediatype).(StreamFormatter); ok {
+		return p.streamPrinter().safeNewStream(sf)
+	}
+	return nil
+}
+
+func (p *printer) safeNewStream(sf StreamFormatter) (stream *bodyStream) {
+	defer func() {
+		if e := recover(); e != nil {
+			p.printf("* panic while formatting body: %v\n", e)
+			stream = nil
+		}
+	}()
+	stream = &bodyStream{p: p}
+	if lf, ok := sf.(limitedStreamFormatter); ok {
+		stream.w = lf.newLimitedStream(printerWriter{p}, p.streamLimit())
+		return stream
+	}
+	stream.w = sf.NewStream(printerWriter{p})
+	return stream
+}
+
+// limitedStreamFormatter is implemented by stream formatters that can limit how much of a record,
+// such as a line or a message, they buffer until it is complete. Longer records are skipped.
+type limitedStreamFormatter interface {
+	newLimitedStream(w io.Writer, max int64) io.WriteCloser
+}
+
+// streamLimit returns the maximum size of a record of a streamed body, as streams have no known length.
+func (p *printer) streamLimit() int64 {
+	if p.logger.MaxResponseBody > 0 {
+		return p.logger.MaxResponseBody
+	}
+	return maxDefaultUnknownReadable
+}
+
+// startServerResponseStream prints the response header once the handler starts writing the body,
+// and returns a stream for printing the body as it is written, if it can be streamed.
+func (p *printer) startServerResponseStream(req *http.Request, rec *responseRecorder) *bodyStream {
+	skip, err := p.checkBodyFiltered(rec.Header())
+	if err != nil {
+		p.printf("* %s\n", p.format(color.FgRed, "error on response body filter: ", err.Error()))
+	}
+	if skip {
+		return nil
+	}
+	stream := p.newBodyStream(rec.Header().Get("Content-Type"))
+	if stream == nil {
+		return nil
+	}
+	if p.logger.ResponseHeader {
+		p.printResponseHeader(req.Proto, fmt.Sprintf("%d %s", rec.statusCode, http.StatusText(rec.statusCode)), rec.Header())
+		p.maybeOnReady()
+	}
+	return stream
+}
+
 func (p *printer) printServerResponse(req *http.Request, rec *responseRecorder) {
+	if rec.stream != nil {
+		// response header and body were printed as the handler wrote them, and the trailer is set once it returns.
+		rec.stream.onClose = func() {
+			p.printHeaderSummary(rec.Header(), serverTrailers(rec.Header()))
+		}
+		rec.stream.Close()
+		return
+	}
+	var body *printer
+	if p.logger.ResponseBody && rec.size != 0 {
+		body = p.bodyPrinter()
+		body.request = p.request
+		body.printServerResponseBody(req, rec)
+	}
+	p.printResponseSummary(body, rec.Header(), serverTrailers(rec.Header()))
 	if p.logger.ResponseHeader {
 		// TODO(henvic): see how httptest.ResponseRecorder adds extra headers due to Content-Type detection
 		// and other stuff (Date). It would be interesting to show them here too (either as default or opt-in).
 		p.printResponseHeader(req.Proto, fmt.Sprintf("%d %s", rec.statusCode, http.StatusText(rec.statusCode)), rec.Header())
 	}
-	if !p.logger.ResponseBody || rec.size == 0 {
-		return
-	}
+	p.printBody(body)
+}
+
+func (p *printer) printServerResponseBody(req *http.Request, rec *responseRecorder) {
 	skip, err := p.checkBodyFiltered(rec.Header())
 	if err != nil {
 		p.printf("* %s\n", p.format(color.FgRed, "error on response body filter: ", err.Error()))
@@ -410,51 +589,148 @@ func (p *printer) printServerResponse(req *http.Request, rec *responseRecorder)
 	if skip {
 		return
 	}
-	if mediatype := req.Header.Get("Content-Type"); mediatype != "" && isBinaryMediatype(mediatype) {
+	if p.skipBinaryContentType(rec.Header().Get("Content-Type")) {
+		if p.logger.HexDump > 0 && rec.buf != nil {
+			// only the head of the body is recorded.
+			p.printBinaryHead(rec.buf.Bytes(), rec.size)
+			return
+		}
 		p.println("* body contains binary data")
 		return
 	}
-	if p.logger.MaxResponseBody > 0 && rec.size > p.logger.MaxResponseBody {
-		p.printf("* body is too long (%d bytes) to print, skipping (longer than %d bytes)\n", rec.size, p.logger.MaxResponseBody)
+	if rec.maxReadableBody > 0 && rec.size > rec.maxReadableBody {
+		p.printf("* body is too long (%d bytes) to print, skipping (longer than %d bytes)\n", rec.size, rec.maxReadableBody)
 		return
 	}
-	p.printBodyReader(rec.Header().Get("Content-Type"), rec.buf)
+	p.printBodyReader(rec.Header(), rec.maxReadableBody, rec.buf)
 }
 
 func (p *printer) printResponseHeader(proto, status string, h http.Header) {
 	p.printf("< %s %s\n",
-		p.format(color.FgBlue, color.Bold, proto),
-		p.format(color.FgRed, status))
+		p.format(color.FgBlue, color.Bold, p.escapeLine(proto)),
+		p.format(color.FgRed, p.escapeLine(status)))
 	p.printHeaders('<', h)
 	p.println()
 }
 
-func (p *printer) printBodyReader(contentType string, r io.Reader) {
-	mediatype, _, _ := mime.ParseMediaType(contentType)
+// printBodyReader prints a body, which is limited to maxLength bytes once decoded.
+func (p *printer) printBodyReader(h http.Header, maxLength int64, r io.Reader) {
+	mediatype, params, _ := mime.ParseMediaType(h.Get("Content-Type"))
 	body, err := io.ReadAll(r)
 	if err != nil {
 		p.printf("* cannot read body: %v\n", p.format(color.FgRed, err.Error()))
 		return
 	}
-	if isBinary(body) {
+	captured := body
+	var ok bool
+	if ce := h.Get("Content-Encoding"); ce != "" {
+		if body, ok = p.decodeBody(ce, body, maxLength); !ok {
+			return
+		}
+	}
+	f := p.findFormatter(mediatype)
+	if !isBinaryFormatter(f) {
+		body = p.decodeCharset(mediatype, params["charset"], body)
+	}
+	if body, ok = p.redactBody(mediatype, params, body); !ok {
+		return
+	}
+	if p.logger.DecodeJWT && jsonTypeRE.MatchString(mediatype) {
+		body = p.decodeJWTFields(body)
+	}
+	binary := isBinary(body)
+	if (binary || isBinaryMediatype(mediatype)) && !isBinaryFormatter(f) && p.logger.HexDump > 0 {
+		p.printHexDump(body, captured)
+		return
+	}
+	if binary && !isBinaryFormatter(f) {
 		p.println("* body contains binary data")
 		return
 	}
+	if f == nil {
+		p.println(p.escape(p.scrubPII(string(body))))
+		return
+	}
+	p.body = body
+	var formatted bytes.Buffer
+	switch err := p.safeBodyFormat(f, &formatted, body); {
+	case err != nil && binary && p.logger.HexDump > 0:
+		p.printf("* body cannot be formatted: %v\n", p.format(color.FgRed, err.Error()))
+		p.printHexDump(body, captured)
+	case err != nil && binary:
+		p.printf("* body cannot be formatted: %v\n", p.format(color.FgRed, err.Error()))
+		p.println("* body contains binary data")
+	case err != nil:
+		p.printf("* body cannot be formatted: %v\n%s\n", p.format(color.FgRed, err.Error()), p.escape(p.scrubPII(string(body))))
+	case p.logger.Colors && isColorFormatter(f):
+		// color formatters print escape sequences setting colors of their own.
+		p.println(p.escapeExceptColors(p.scrubPII(formatted.String())))
+	default:
+		p.println(p.escape(p.scrubPII(formatted.String())))
+	}
+	if s, ok := f.(Summarizer); ok {
+		p.summary = p.escapeLine(p.scrubPII(p.safeBodySummarize(s, mediatype, body)))
+	}
+}
+
+// bodySizeLimiter is implemented by formatters limiting the size of the bodies they format,
+// so that large bodies aren't buffered for nothing.
+type bodySizeLimiter interface {
+	maxBodySize() int64
+}
+
+// bodyLimit returns the maximum size of a body to read for printing, which its formatter might lower.
+func (p *printer) bodyLimit(contentType string, max int64) int64 {
+	if !slices.ContainsFunc(p.logger.Formatters, isBodySizeLimiter) {
+		return max
+	}
+	mediatype, _, _ := mime.ParseMediaType(contentType)
+	l, ok := p.findFormatter(mediatype).(bodySizeLimiter)
+	if !ok {
+		return max
+	}
+	if limit := l.maxBodySize(); max <= 0 || limit < max {
+		return limit
+	}
+	return max
+}
+
+// findFormatter returns the first formatter matching the media type, if any.
+func (p *printer) findFormatter(mediatype string) Formatter {
 	for _, f := range p.logger.Formatters {
-		if ok := p.safeBodyMatch(f, mediatype); !ok {
-			continue
+		if ok := p.safeBodyMatch(f, mediatype); ok {
+			return f
 		}
-		var formatted bytes.Buffer
-		switch err := p.safeBodyFormat(f, &formatted, body); {
-		case err != nil:
-			p.printf("* body cannot be formatted: %v\n%s\n", p.format(color.FgRed, err.Error()), string(body))
-		default:
-			p.println(formatted.String())
-		}
-		return
 	}
+	return nil
+}
+
+func isBodySizeLimiter(f Formatter) bool {
+	_, ok := f.(bodySizeLimiter)
+	return ok
+}
+
+func isColorFormatter(f Formatter) bool {
+	_, ok := f.(ColorFormatter)
+	return ok
+}
+
+func isBinaryFormatter(f Formatter) bool {
+	bf, ok := f.(BinaryFormatter)
+	return ok && bf.AcceptsBinary()
+}
 
-	p.println(string(body))
+// skipBinaryContentType checks if a body should be skipped because its media type is binary
+// and no BinaryFormatter matches it.
+func (p *printer) skipBinaryContentType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediatype, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediatype = contentType
+	}
+	return isBinaryMediatype(mediatype) && !isBinaryFormatter(p.findFormatter(mediatype))
 }
 
 func (p *printer) safeBodyMatch(f Formatter, mediatype string) bool {
@@ -473,9 +749,24 @@ func (p *printer) safeBodyFormat(f Formatter, w io.Writer, src []byte) (err erro
 			err = fmt.Errorf("panic: %v", e)
 		}
 	}()
+	if rf, ok := f.(ResponseFormatter); ok && p.request != nil {
+		return rf.FormatResponse(w, p.request, src)
+	}
+	if cf, ok := f.(ColorFormatter); ok && p.logger.Colors {
+		return cf.FormatColors(w, src)
+	}
 	return f.Format(w, src)
 }
 
+func (p *printer) safeBodySummarize(s Summarizer, mediatype string, src []byte) string {
+	defer func() {
+		if e := recover(); e != nil {
+			p.printf("* panic while summarizing body: %v\n", e)
+		}
+	}()
+	return s.Summarize(mediatype, src)
+}
+
 func (p *printer) format(s ...interface{}) string {
 	if p.logger.Colors {
 		return color.Format(s...)
@@ -483,9 +774,37 @@ func (p *printer) format(s ...interface{}) string {
 	return color.StripAttributes(s...)
 }
 
+// escape control characters of untrusted text, unless SkipEscape is set.
+func (p *printer) escape(s string) string {
+	if p.logger.SkipEscape {
+		return s
+	}
+	return color.Escape(s)
+}
+
+// escapeExceptColors keeps the escape sequences setting colors printed by color formatters.
+func (p *printer) escapeExceptColors(s string) string {
+	if p.logger.SkipEscape {
+		return s
+	}
+	return color.EscapeExceptColors(s)
+}
+
+// escapeLine escapes newlines too, for untrusted text printed on a single line, such as header values.
+func (p *printer) escapeLine(s string) string {
+	if p.logger.SkipEscape {
+		return s
+	}
+	return strings.ReplaceAll(color.Escape(s), "\n", `\n`)
+}
+
 func (p *printer) printHeaders(prefix rune, h http.Header) {
 	if !p.logger.SkipSanitize {
-		h = header.Sanitize(header.DefaultSanitizers, h)
+		sanitizers := p.logger.getHeaderSanitizers()
+		h = p.sanitizeHeaders(sanitizers, h)
+		if p.logger.DetectSecrets {
+			h = header.SanitizeSecrets(sanitizers, h, p.logger.getRedactor())
+		}
 	}
 
 	longest, sorted := sortHeaderKeys(h, p.logger.cloneSkipHeader())
@@ -496,12 +815,64 @@ func (p *printer) printHeaders(prefix rune, h http.Header) {
 				pad = strings.Repeat(" ", longest-len(key))
 			}
 			p.printf("%c %s%s %s%s\n", prefix,
-				p.format(color.FgBlue, color.Bold, key),
+				p.format(color.FgBlue, color.Bold, p.escapeLine(key)),
 				p.format(color.FgRed, ":"),
 				pad,
-				p.format(color.FgYellow, v))
+				p.format(color.FgYellow, p.escapeLine(p.scrubHeaderPII(key, v))))
+		}
+	}
+}
+
+// sanitizeHeaders is like header.Sanitize, but redacts the values of headers whose sanitizer panics,
+// as sanitizers can be set with SetHeaderSanitizer.
+func (p *printer) sanitizeHeaders(sanitizers map[string]header.SanitizeHeaderFunc, h http.Header) http.Header {
+	redacted := http.Header{}
+	for k, values := range h {
+		s, ok := sanitizers[http.CanonicalHeaderKey(k)]
+		if !ok {
+			redacted[k] = values
+			continue
+		}
+		list := []string{}
+		for _, v := range values {
+			list = append(list, p.safeSanitize(k, s, v))
+		}
+		redacted[k] = list
+	}
+	return redacted
+}
+
+func (p *printer) safeSanitize(key string, s header.SanitizeHeaderFunc, value string) (sanitized string) {
+	defer func() {
+		if e := recover(); e != nil {
+			p.printf("* panic while sanitizing %s header: %v\n", p.escapeLine(key), e)
+			sanitized = p.logger.getRedactor()(value)
 		}
+	}()
+	return s(value)
+}
+
+// sanitizeURL redacts the userinfo and the query parameters carrying credentials of a URL.
+// If DecodeBasicAuth is set, the username is kept. Control characters are escaped.
+func (p *printer) sanitizeURL(u *url.URL) string {
+	u = p.scrubQueryPII(u)
+	if p.logger.SkipSanitize {
+		return p.escapeLine(p.scrubURLPII(u.String(), u.Host != ""))
 	}
+	s := header.SanitizeURL(u, p.logger.getQueryParams(), p.logger.DecodeBasicAuth, p.logger.getRedactor())
+	return p.escapeLine(p.scrubURLPII(s, u.Host != ""))
+}
+
+// requestURI returns the URI of the request line, with the query parameters carrying credentials redacted
+// and control characters escaped.
+func (p *printer) requestURI(u *url.URL) string {
+	u = p.scrubQueryPII(u)
+	if p.logger.SkipSanitize {
+		return p.escapeLine(p.scrubURLPII(u.RequestURI(), false))
+	}
+	redacted := *u
+	redacted.RawQuery = header.RedactQuery(u.RawQuery, p.logger.getQueryParams(), p.logger.getRedactor())
+	return p.escapeLine(p.scrubURLPII(redacted.RequestURI(), false))
 }
 
 func sortHeaderKeys(h http.Header, skipped map[string]struct{}) (int, []string) {
@@ -527,9 +898,9 @@ func sortHeaderKeys(h http.Header, skipped map[string]struct{}) (int, []string)
 
 func (p *printer) printRequestHeader(req *http.Request) {
 	p.printf("> %s %s %s\n",
-		p.format(color.FgBlue, color.Bold, req.Method),
-		p.format(color.FgYellow, req.URL.RequestURI()),
-		p.format(color.FgBlue, req.Proto))
+		p.format(color.FgBlue, color.Bold, p.escapeLine(req.Method)),
+		p.format(color.FgYellow, p.requestURI(req.URL)),
+		p.format(color.FgBlue, p.escapeLine(req.Proto)))
 	p.printHeaders('>', addRequestHeaders(req))
 	p.println()
 }
@@ -567,17 +938,21 @@ func (p *printer) printRequestBody(req *http.Request) {
 	if skip {
 		return
 	}
-	if mediatype := req.Header.Get("Content-Type"); mediatype != "" && isBinaryMediatype(mediatype) {
+	if p.skipBinaryContentType(req.Header.Get("Content-Type")) {
+		if p.logger.HexDump > 0 {
+			req.Body = p.printHexDumpHead(req.Body, req.ContentLength, p.logger.MaxRequestBody)
+			return
+		}
 		p.println("* body contains binary data")
 		return
 	}
 	// TODO(henvic): add support for printing multipart/formdata information as body (to responses too).
-	if p.logger.MaxRequestBody > 0 && req.ContentLength > p.logger.MaxRequestBody {
+	maxBody := p.bodyLimit(req.Header.Get("Content-Type"), p.logger.MaxRequestBody)
+	if maxBody > 0 && req.ContentLength > maxBody {
 		p.printf("* body is too long (%d bytes) to print, skipping (longer than %d bytes)\n",
-			req.ContentLength, p.logger.MaxRequestBody)
+			req.ContentLength, maxBody)
 		return
 	}
-	contentType := req.Header.Get("Content-Type")
 	if req.ContentLength > 0 {
 		var buf bytes.Buffer
 		tee := io.TeeReader(req.Body, &buf)
@@ -585,10 +960,10 @@ func (p *printer) printRequestBody(req *http.Request) {
 		defer func() {
 			req.Body = io.NopCloser(&buf)
 		}()
-		p.printBodyReader(contentType, tee)
+		p.printBodyReader(req.Header, maxBody, tee)
 		return
 	}
-	if newBody := p.printBodyUnknownLength(contentType, p.logger.MaxRequestBody, req.Body); newBody != nil {
+	if newBody := p.printBodyUnknownLength(req.Header, maxBody, req.Body); newBody != nil {
 		req.Body = newBody
 	}
 }
@@ -0,0 +1,148 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/henvic/httpretty/internal/color"
+)
+
+// ProblemFormatter formats RFC 9457 problem details (application/problem+json).
+//
+// The status, title, and type of the problem are printed on the first line, highlighted when Logger.Colors is set,
+// followed by its detail, instance, and extension members. The first line is also used as the summary
+// printed before the response.
+type ProblemFormatter struct{}
+
+// Match problem+json media type.
+func (pf *ProblemFormatter) Match(mediatype string) bool {
+	return mediatype == "application/problem+json"
+}
+
+// Format problem details.
+func (pf *ProblemFormatter) Format(w io.Writer, src []byte) error {
+	return pf.format(w, src, false)
+}
+
+// FormatColors formats problem details, highlighting their status, title, and type.
+func (pf *ProblemFormatter) FormatColors(w io.Writer, src []byte) error {
+	return pf.format(w, src, true)
+}
+
+// Summarize problem details with their status, title, and type.
+func (pf *ProblemFormatter) Summarize(mediatype string, src []byte) string {
+	p, ok := parseProblem(src)
+	if !ok {
+		return ""
+	}
+	return "problem: " + p.headline()
+}
+
+type problem struct {
+	typ, title, detail, instance string
+	status                       int
+	extensions                   map[string]json.RawMessage
+}
+
+// parseProblem decodes problem details, ignoring standard members of the wrong type, as required by RFC 9457.
+func parseProblem(src []byte) (p problem, ok bool) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(src, &members); err != nil {
+		return p, false
+	}
+	p.extensions = map[string]json.RawMessage{}
+	for name, v := range members {
+		switch name {
+		case "type", "title", "detail", "instance":
+			var s string
+			if err := json.Unmarshal(v, &s); err != nil {
+				continue
+			}
+			switch name {
+			case "type":
+				p.typ = s
+			case "title":
+				p.title = s
+			case "detail":
+				p.detail = s
+			case "instance":
+				p.instance = s
+			}
+		case "status":
+			_ = json.Unmarshal(v, &p.status)
+		default:
+			p.extensions[name] = v
+		}
+	}
+	return p, true
+}
+
+func (p problem) headline() string {
+	var parts []string
+	if p.status != 0 {
+		parts = append(parts, strconv.Itoa(p.status))
+	}
+	switch {
+	case p.title != "":
+		parts = append(parts, p.title)
+	case p.status != 0 && http.StatusText(p.status) != "":
+		parts = append(parts, http.StatusText(p.status))
+	}
+	// about:blank is the default type, and means the problem has no semantics beyond the status code.
+	if p.typ != "" && p.typ != "about:blank" {
+		parts = append(parts, "("+p.typ+")")
+	}
+	if len(parts) == 0 {
+		return "untitled problem"
+	}
+	return strings.Join(parts, " ")
+}
+
+func (pf *ProblemFormatter) format(w io.Writer, src []byte, colors bool) error {
+	p, ok := parseProblem(src)
+	if !ok {
+		// not an object: indent it like JSONFormatter does.
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, src, "", "    "); err != nil {
+			return err
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+	var buf bytes.Buffer
+	if colors {
+		// escaped, so that the members cannot set colors of their own.
+		p.typ, p.title, p.detail, p.instance = color.Escape(p.typ), color.Escape(p.title), color.Escape(p.detail), color.Escape(p.instance)
+	}
+	headline := p.headline()
+	if colors {
+		headline = color.Format(color.FgRed, color.Bold, headline)
+	}
+	buf.WriteString(headline + "\n")
+	if p.detail != "" {
+		fmt.Fprintf(&buf, "detail: %s\n", p.detail)
+	}
+	if p.instance != "" {
+		fmt.Fprintf(&buf, "instance: %s\n", p.instance)
+	}
+	names := make([]string, 0, len(p.extensions))
+	for name := range p.extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var v bytes.Buffer
+		if err := json.Indent(&v, p.extensions[name], "", "    "); err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", name, v.String())
+	}
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
@@ -0,0 +1,84 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+const testProblem = `{
+	"type": "https://example.com/probs/out-of-credit",
+	"title": "You do not have enough credit.",
+	"status": 403,
+	"detail": "Your current balance is 30, but that costs 50.",
+	"instance": "/account/12345/msgs/abc",
+	"balance": 30,
+	"accounts": ["/account/12345", "/account/67890"]
+}`
+
+func TestProblemFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc    string
+		src     string
+		want    string
+		summary string
+	}{
+		{
+			desc: "problem",
+			src:  testProblem,
+			want: `403 You do not have enough credit. (https://example.com/probs/out-of-credit)
+detail: Your current balance is 30, but that costs 50.
+instance: /account/12345/msgs/abc
+accounts: [
+    "/account/12345",
+    "/account/67890"
+]
+balance: 30`,
+			summary: "problem: 403 You do not have enough credit. (https://example.com/probs/out-of-credit)",
+		},
+		{
+			desc:    "about:blank",
+			src:     `{"type":"about:blank","status":404}`,
+			want:    "404 Not Found",
+			summary: "problem: 404 Not Found",
+		},
+		{
+			desc:    "invalid members",
+			src:     `{"title":42,"status":"500"}`,
+			want:    "untitled problem",
+			summary: "problem: untitled problem",
+		},
+		{
+			desc: "not an object",
+			src:  `["not","a","problem"]`,
+			want: "[\n    \"not\",\n    \"a\",\n    \"problem\"\n]",
+		},
+	}
+	f := &ProblemFormatter{}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := f.Format(&buf, []byte(tc.src)); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %s, wanted %s", got, tc.want)
+			}
+			if got := f.Summarize("application/problem+json", []byte(tc.src)); got != tc.summary {
+				t.Errorf("got summary %q, wanted %q", got, tc.summary)
+			}
+		})
+	}
+}
+
+func TestProblemFormatterColors(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	if err := (&ProblemFormatter{}).FormatColors(&buf, []byte(`{"status":429,"title":"Slow down"}`)); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	if got, want := buf.String(), "\x1b[31;1m429 Slow down\x1b[0m"; got != want {
+		t.Errorf("got formatted body %q, wanted %q", got, want)
+	}
+}
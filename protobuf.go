@@ -0,0 +1,537 @@
+package httpretty
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProtobufFormatter decodes protocol buffers without requiring their schema or generated code.
+//
+// Messages are printed as a tree of field numbers, similar to protoc --decode_raw.
+// Length-delimited fields are guessed to be strings, bytes, or nested messages.
+//
+// If Descriptors and MessageType are set, real field names, types, and enum values are used instead.
+type ProtobufFormatter struct {
+	// Descriptors of the messages, loaded from a FileDescriptorSet, such as one created with
+	// protoc --include_imports --descriptor_set_out.
+	Descriptors *ProtobufDescriptors
+
+	// MessageType is the fully-qualified name of the message of the bodies, such as "acme.v1.User".
+	MessageType string
+}
+
+var protobufMediatypes = map[string]struct{}{
+	"application/x-protobuf":          {},
+	"application/protobuf":            {},
+	"application/x-google-protobuf":   {},
+	"application/vnd.google.protobuf": {},
+	"application/proto":               {},
+}
+
+// Match protocol buffers media types.
+func (pf *ProtobufFormatter) Match(mediatype string) bool {
+	_, ok := protobufMediatypes[mediatype]
+	return ok
+}
+
+// AcceptsBinary data.
+func (pf *ProtobufFormatter) AcceptsBinary() bool {
+	return true
+}
+
+// Format protocol buffers message.
+func (pf *ProtobufFormatter) Format(w io.Writer, src []byte) error {
+	var msg *protoMessageDesc
+	if pf.Descriptors != nil && pf.MessageType != "" {
+		var ok bool
+		if msg, ok = pf.Descriptors.messages[strings.TrimPrefix(pf.MessageType, ".")]; !ok {
+			return fmt.Errorf("protobuf message type %q not found", pf.MessageType)
+		}
+	}
+	fields, err := parseProto(src, 0)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	pf.writeFields(&buf, fields, msg, 0)
+	_, err = w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// ProtobufDescriptors describes protocol buffers messages and enums.
+type ProtobufDescriptors struct {
+	messages map[string]*protoMessageDesc
+	enums    map[string]map[uint64]string
+}
+
+// LoadFileDescriptorSet reads a file containing a serialized google.protobuf.FileDescriptorSet.
+func LoadFileDescriptorSet(name string) (*ProtobufDescriptors, error) {
+	b, err := os.ReadFile(name) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	return ParseFileDescriptorSet(b)
+}
+
+// ParseFileDescriptorSet parses a serialized google.protobuf.FileDescriptorSet.
+func ParseFileDescriptorSet(b []byte) (*ProtobufDescriptors, error) {
+	d := &ProtobufDescriptors{
+		messages: map[string]*protoMessageDesc{},
+		enums:    map[string]map[uint64]string{},
+	}
+	set, err := parseProto(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse FileDescriptorSet: %w", err)
+	}
+	for _, file := range set {
+		if file.number != 1 || file.wireType != protoBytes {
+			continue
+		}
+		fileFields, err := parseProto(file.bytes, 0)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse FileDescriptorProto: %w", err)
+		}
+		var pkg string
+		for _, f := range fileFields {
+			if f.number == 2 && f.wireType == protoBytes {
+				pkg = string(f.bytes)
+			}
+		}
+		for _, f := range fileFields {
+			var err error
+			switch {
+			case f.number == 4 && f.wireType == protoBytes:
+				err = d.addMessage(pkg, f.bytes)
+			case f.number == 5 && f.wireType == protoBytes:
+				err = d.addEnum(pkg, f.bytes)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d, nil
+}
+
+func joinProtoName(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// addMessage adds a DescriptorProto, and its nested types.
+func (d *ProtobufDescriptors) addMessage(scope string, b []byte) error {
+	fields, err := parseProto(b, 0)
+	if err != nil {
+		return fmt.Errorf("cannot parse DescriptorProto: %w", err)
+	}
+	msg := &protoMessageDesc{fields: map[uint64]*protoFieldDesc{}}
+	for _, f := range fields {
+		if f.number == 1 && f.wireType == protoBytes {
+			msg.name = joinProtoName(scope, string(f.bytes))
+		}
+	}
+	d.messages[msg.name] = msg
+	for _, f := range fields {
+		if f.wireType != protoBytes {
+			continue
+		}
+		switch f.number {
+		case 2:
+			field, err := parseProtoFieldDesc(f.bytes)
+			if err != nil {
+				return err
+			}
+			msg.fields[field.number] = field
+		case 3:
+			err = d.addMessage(msg.name, f.bytes)
+		case 4:
+			err = d.addEnum(msg.name, f.bytes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addEnum adds an EnumDescriptorProto.
+func (d *ProtobufDescriptors) addEnum(scope string, b []byte) error {
+	fields, err := parseProto(b, 0)
+	if err != nil {
+		return fmt.Errorf("cannot parse EnumDescriptorProto: %w", err)
+	}
+	var name string
+	values := map[uint64]string{}
+	for _, f := range fields {
+		switch {
+		case f.number == 1 && f.wireType == protoBytes:
+			name = joinProtoName(scope, string(f.bytes))
+		case f.number == 2 && f.wireType == protoBytes:
+			value, err := parseProto(f.bytes, 0)
+			if err != nil {
+				return fmt.Errorf("cannot parse EnumValueDescriptorProto: %w", err)
+			}
+			var (
+				valueName string
+				number    uint64
+			)
+			for _, v := range value {
+				switch {
+				case v.number == 1 && v.wireType == protoBytes:
+					valueName = string(v.bytes)
+				case v.number == 2 && v.wireType == protoVarint:
+					number = v.value
+				}
+			}
+			values[number] = valueName
+		}
+	}
+	d.enums[name] = values
+	return nil
+}
+
+func parseProtoFieldDesc(b []byte) (*protoFieldDesc, error) {
+	fields, err := parseProto(b, 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse FieldDescriptorProto: %w", err)
+	}
+	field := &protoFieldDesc{}
+	for _, f := range fields {
+		switch {
+		case f.number == 1 && f.wireType == protoBytes:
+			field.name = string(f.bytes)
+		case f.number == 3 && f.wireType == protoVarint:
+			field.number = f.value
+		case f.number == 4 && f.wireType == protoVarint:
+			field.repeated = f.value == 3 // LABEL_REPEATED
+		case f.number == 5 && f.wireType == protoVarint:
+			field.typ = int(f.value)
+		case f.number == 6 && f.wireType == protoBytes:
+			field.typeName = strings.TrimPrefix(string(f.bytes), ".")
+		}
+	}
+	return field, nil
+}
+
+type protoMessageDesc struct {
+	name   string
+	fields map[uint64]*protoFieldDesc
+}
+
+type protoFieldDesc struct {
+	name     string
+	number   uint64
+	repeated bool
+	typ      int
+	typeName string
+}
+
+// Field types, as defined by google.protobuf.FieldDescriptorProto.Type.
+// Types not listed here are formatted as unsigned integers or nested messages.
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+)
+
+// Wire types.
+const (
+	protoVarint     = 0
+	protoFixed64    = 1
+	protoBytes      = 2
+	protoStartGroup = 3
+	protoEndGroup   = 4
+	protoFixed32    = 5
+)
+
+// protoMaxDepth limits the nesting of messages and groups.
+const protoMaxDepth = 64
+
+type protoField struct {
+	number   uint64
+	wireType int
+	value    uint64       // varint, fixed32, and fixed64 values
+	bytes    []byte       // length-delimited values
+	group    []protoField // group fields
+}
+
+var errProtoTruncated = errors.New("protobuf: unexpected end of data")
+
+func readProtoVarint(b []byte) (v uint64, n int, err error) {
+	for shift := uint(0); shift < 64; shift += 7 {
+		if n >= len(b) {
+			return 0, 0, errProtoTruncated
+		}
+		c := b[n]
+		n++
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v, n, nil
+		}
+	}
+	return 0, 0, errors.New("protobuf: varint overflow")
+}
+
+// parseProto decodes the fields of a message from the wire format.
+func parseProto(b []byte, depth int) ([]protoField, error) {
+	fields, n, err := parseProtoFields(b, depth, 0)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(b) {
+		return nil, errors.New("protobuf: unexpected end group")
+	}
+	return fields, nil
+}
+
+// parseProtoFields decodes fields until the end of data, or the end of the given group.
+func parseProtoFields(b []byte, depth int, group uint64) (fields []protoField, read int, err error) {
+	if depth > protoMaxDepth {
+		return nil, 0, errors.New("protobuf: exceeded maximum nesting depth")
+	}
+	for read < len(b) {
+		tag, n, err := readProtoVarint(b[read:])
+		if err != nil {
+			return nil, 0, err
+		}
+		read += n
+		f := protoField{
+			number:   tag >> 3,
+			wireType: int(tag & 7),
+		}
+		if f.number == 0 || f.number > 1<<29-1 {
+			return nil, 0, fmt.Errorf("protobuf: invalid field number %d", f.number)
+		}
+		switch f.wireType {
+		case protoVarint:
+			if f.value, n, err = readProtoVarint(b[read:]); err != nil {
+				return nil, 0, err
+			}
+			read += n
+		case protoFixed64:
+			if len(b)-read < 8 {
+				return nil, 0, errProtoTruncated
+			}
+			for i := 7; i >= 0; i-- {
+				f.value = f.value<<8 | uint64(b[read+i])
+			}
+			read += 8
+		case protoFixed32:
+			if len(b)-read < 4 {
+				return nil, 0, errProtoTruncated
+			}
+			for i := 3; i >= 0; i-- {
+				f.value = f.value<<8 | uint64(b[read+i])
+			}
+			read += 4
+		case protoBytes:
+			l, n, err := readProtoVarint(b[read:])
+			if err != nil {
+				return nil, 0, err
+			}
+			read += n
+			if l > uint64(len(b)-read) {
+				return nil, 0, errProtoTruncated
+			}
+			f.bytes = b[read : read+int(l)]
+			read += int(l)
+		case protoStartGroup:
+			if f.group, n, err = parseProtoFields(b[read:], depth+1, f.number); err != nil {
+				return nil, 0, err
+			}
+			read += n
+		case protoEndGroup:
+			if f.number != group {
+				return nil, 0, errors.New("protobuf: unexpected end group")
+			}
+			return fields, read, nil
+		default:
+			return nil, 0, fmt.Errorf("protobuf: invalid wire type %d", f.wireType)
+		}
+		fields = append(fields, f)
+	}
+	if group != 0 {
+		return nil, 0, errProtoTruncated
+	}
+	return fields, read, nil
+}
+
+func (pf *ProtobufFormatter) writeFields(buf *bytes.Buffer, fields []protoField, msg *protoMessageDesc, depth int) {
+	indent := strings.Repeat("    ", depth)
+	for _, f := range fields {
+		var desc *protoFieldDesc
+		if msg != nil {
+			desc = msg.fields[f.number]
+		}
+		name := strconv.FormatUint(f.number, 10)
+		if desc != nil {
+			name = desc.name
+		}
+		switch {
+		case f.wireType == protoStartGroup:
+			fmt.Fprintf(buf, "%s%s {\n", indent, name)
+			pf.writeFields(buf, f.group, pf.message(desc), depth+1)
+			fmt.Fprintf(buf, "%s}\n", indent)
+		case f.wireType != protoBytes:
+			fmt.Fprintf(buf, "%s%s: %s\n", indent, name, pf.scalar(f.wireType, f.value, desc))
+		case desc != nil && desc.typ == protoTypeMessage:
+			nested, err := parseProto(f.bytes, depth+1)
+			if err != nil {
+				fmt.Fprintf(buf, "%s%s: %s\n", indent, name, strconv.Quote(string(f.bytes)))
+				continue
+			}
+			fmt.Fprintf(buf, "%s%s {\n", indent, name)
+			pf.writeFields(buf, nested, pf.message(desc), depth+1)
+			fmt.Fprintf(buf, "%s}\n", indent)
+		case desc != nil && (desc.typ == protoTypeString || desc.typ == protoTypeBytes):
+			fmt.Fprintf(buf, "%s%s: %s\n", indent, name, strconv.Quote(string(f.bytes)))
+		case desc != nil && desc.repeated:
+			// packed repeated scalars.
+			for _, v := range pf.unpack(f.bytes, desc) {
+				fmt.Fprintf(buf, "%s%s: %s\n", indent, name, v)
+			}
+		default:
+			pf.writeGuess(buf, indent, name, f.bytes, depth)
+		}
+	}
+}
+
+// writeGuess writes a length-delimited value of unknown type.
+func (pf *ProtobufFormatter) writeGuess(buf *bytes.Buffer, indent, name string, b []byte, depth int) {
+	if len(b) == 0 {
+		fmt.Fprintf(buf, "%s%s: \"\"\n", indent, name)
+		return
+	}
+	// nested messages of most fields start with a tag byte that isn't printable.
+	printable := isPrintableText(b)
+	if !printable || b[0] < 0x20 {
+		if nested, err := parseProto(b, depth+1); err == nil && len(nested) != 0 {
+			fmt.Fprintf(buf, "%s%s {\n", indent, name)
+			pf.writeFields(buf, nested, nil, depth+1)
+			fmt.Fprintf(buf, "%s}\n", indent)
+			return
+		}
+	}
+	if printable {
+		fmt.Fprintf(buf, "%s%s: %s\n", indent, name, strconv.Quote(string(b)))
+		return
+	}
+	fmt.Fprintf(buf, "%s%s: %s (%d bytes)\n", indent, name, strconv.Quote(string(b)), len(b))
+}
+
+func isPrintableText(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, c := range b {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+func (pf *ProtobufFormatter) message(desc *protoFieldDesc) *protoMessageDesc {
+	if desc == nil || pf.Descriptors == nil {
+		return nil
+	}
+	return pf.Descriptors.messages[desc.typeName]
+}
+
+// scalar formats a varint, fixed32, or fixed64 value.
+func (pf *ProtobufFormatter) scalar(wireType int, v uint64, desc *protoFieldDesc) string {
+	typ := 0
+	if desc != nil {
+		typ = desc.typ
+	}
+	switch {
+	case typ == protoTypeInt32:
+		return strconv.FormatInt(int64(int32(v)), 10)
+	case typ == protoTypeInt64:
+		return strconv.FormatInt(int64(v), 10)
+	case typ == protoTypeSint32, typ == protoTypeSint64:
+		return strconv.FormatInt(int64(v>>1)^-int64(v&1), 10)
+	case typ == protoTypeBool:
+		return strconv.FormatBool(v != 0)
+	case typ == protoTypeEnum:
+		if pf.Descriptors != nil {
+			if name, ok := pf.Descriptors.enums[desc.typeName][v]; ok {
+				return name
+			}
+		}
+		return strconv.FormatInt(int64(int32(v)), 10)
+	case typ == protoTypeDouble:
+		return strconv.FormatFloat(math.Float64frombits(v), 'g', -1, 64)
+	case typ == protoTypeFloat:
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(v))), 'g', -1, 32)
+	case typ == protoTypeSfixed32:
+		return strconv.FormatInt(int64(int32(v)), 10)
+	case typ == protoTypeSfixed64:
+		return strconv.FormatInt(int64(v), 10)
+	case typ != 0:
+		return strconv.FormatUint(v, 10)
+	case wireType == protoFixed32:
+		return fmt.Sprintf("0x%08x", v)
+	case wireType == protoFixed64:
+		return fmt.Sprintf("0x%016x", v)
+	default:
+		return strconv.FormatUint(v, 10)
+	}
+}
+
+// unpack decodes packed repeated scalar values.
+func (pf *ProtobufFormatter) unpack(b []byte, desc *protoFieldDesc) []string {
+	var (
+		values   []string
+		wireType = protoVarint
+		size     int
+	)
+	switch desc.typ {
+	case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+		wireType, size = protoFixed64, 8
+	case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+		wireType, size = protoFixed32, 4
+	}
+	for len(b) != 0 {
+		var v uint64
+		if size == 0 {
+			n := 0
+			var err error
+			if v, n, err = readProtoVarint(b); err != nil {
+				return append(values, strconv.Quote(string(b)))
+			}
+			b = b[n:]
+		} else {
+			if len(b) < size {
+				return append(values, strconv.Quote(string(b)))
+			}
+			for i := size - 1; i >= 0; i-- {
+				v = v<<8 | uint64(b[i])
+			}
+			b = b[size:]
+		}
+		values = append(values, pf.scalar(wireType, v, desc))
+	}
+	return values
+}
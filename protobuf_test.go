@@ -0,0 +1,146 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// protoEncoder helps creating protocol buffers messages for testing.
+type protoEncoder []byte
+
+func (e protoEncoder) varint(number, v uint64) protoEncoder {
+	e = binary.AppendUvarint(e, number<<3|protoVarint)
+	return binary.AppendUvarint(e, v)
+}
+
+func (e protoEncoder) fixed32(number uint64, v uint32) protoEncoder {
+	e = binary.AppendUvarint(e, number<<3|protoFixed32)
+	return binary.LittleEndian.AppendUint32(e, v)
+}
+
+func (e protoEncoder) bytes(number uint64, b []byte) protoEncoder {
+	e = binary.AppendUvarint(e, number<<3|protoBytes)
+	e = binary.AppendUvarint(e, uint64(len(b)))
+	return append(e, b...)
+}
+
+func (e protoEncoder) string(number uint64, s string) protoEncoder {
+	return e.bytes(number, []byte(s))
+}
+
+func TestProtobufFormatterRaw(t *testing.T) {
+	t.Parallel()
+	nested := protoEncoder{}.varint(1, 1).string(2, "nested")
+	src := protoEncoder{}.
+		varint(1, 150).
+		string(2, "hello world").
+		bytes(3, nested).
+		fixed32(4, 0x3f800000).
+		bytes(5, []byte{0xff, 0x00}).
+		bytes(6, nil).
+		bytes(7, protoEncoder{}.string(4, "ambiguous"))
+	f := &ProtobufFormatter{}
+	if !f.Match("application/x-protobuf") || !f.AcceptsBinary() {
+		t.Error("expected ProtobufFormatter to match application/x-protobuf binary data")
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, src); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `1: 150
+2: "hello world"
+3 {
+    1: 1
+    2: "nested"
+}
+4: 0x3f800000
+5: "\xff\x00" (2 bytes)
+6: ""
+7: "\"\tambiguous"`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+}
+
+func TestProtobufFormatterInvalid(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		src  []byte
+		want string
+	}{
+		{"truncated", protoEncoder{}.string(1, "hello")[:4], "protobuf: unexpected end of data"},
+		{"wire type", []byte{0x0f}, "protobuf: invalid wire type 7"},
+		{"field number", []byte{0x00}, "protobuf: invalid field number 0"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (&ProtobufFormatter{}).Format(&buf, tc.src); err == nil || err.Error() != tc.want {
+				t.Errorf("got format error = %v, wanted %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestProtobufFormatterDescriptors(t *testing.T) {
+	t.Parallel()
+	field := func(name string, number, label, typ uint64, typeName string) []byte {
+		e := protoEncoder{}.string(1, name).varint(3, number).varint(4, label).varint(5, typ)
+		if typeName != "" {
+			e = e.string(6, typeName)
+		}
+		return e
+	}
+	status := protoEncoder{}.string(1, "Status").
+		bytes(2, protoEncoder{}.string(1, "UNKNOWN").varint(2, 0)).
+		bytes(2, protoEncoder{}.string(1, "ACTIVE").varint(2, 1))
+	address := protoEncoder{}.string(1, "Address").
+		bytes(2, field("city", 1, 1, protoTypeString, ""))
+	user := protoEncoder{}.string(1, "User").
+		bytes(2, field("id", 1, 1, protoTypeInt64, "")).
+		bytes(2, field("name", 2, 1, protoTypeString, "")).
+		bytes(2, field("status", 3, 1, protoTypeEnum, ".acme.v1.Status")).
+		bytes(2, field("address", 4, 1, protoTypeMessage, ".acme.v1.User.Address")).
+		bytes(2, field("scores", 5, 3, protoTypeSint32, "")).
+		bytes(3, address)
+	file := protoEncoder{}.string(1, "user.proto").string(2, "acme.v1").bytes(4, user).bytes(5, status)
+	descriptors, err := ParseFileDescriptorSet(protoEncoder{}.bytes(1, file))
+	if err != nil {
+		t.Fatalf("cannot parse FileDescriptorSet: %v", err)
+	}
+
+	src := protoEncoder{}.
+		varint(1, 1<<64-1).
+		string(2, "Gopher").
+		varint(3, 1).
+		bytes(4, protoEncoder{}.string(1, "Florianópolis")).
+		bytes(5, []byte{0x01, 0x04}).
+		varint(9, 7)
+	f := &ProtobufFormatter{
+		Descriptors: descriptors,
+		MessageType: "acme.v1.User",
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, src); err != nil {
+		t.Errorf("got format error = %v, wanted nil", err)
+	}
+	want := `id: -1
+name: "Gopher"
+status: ACTIVE
+address {
+    city: "Florianópolis"
+}
+scores: -1
+scores: 2
+9: 7`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %s, wanted %s", got, want)
+	}
+
+	f.MessageType = "acme.v1.Unknown"
+	if err := f.Format(&buf, src); err == nil || err.Error() != `protobuf message type "acme.v1.Unknown" not found` {
+		t.Errorf("got format error = %v, wanted message type not found", err)
+	}
+}
@@ -0,0 +1,169 @@
+package httpretty
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/henvic/httpretty/internal/color"
+)
+
+// printProxy prints the proxy used for a request, and why it was chosen or skipped.
+func (p *printer) printProxy(transport *http.Transport, req *http.Request) {
+	proxyURL, err := transport.Proxy(req)
+	if err != nil {
+		p.printf("* cannot get proxy: %s\n", p.format(color.FgRed, err.Error()))
+		return
+	}
+	fromEnvironment := isProxyFromEnvironment(proxyURL, req)
+	getenv := proxyGetenv()
+	if proxyURL == nil {
+		if fromEnvironment {
+			if reason := noProxyReason(req.URL, getenv); reason != "" {
+				p.printf("* Not using proxy: %s\n", p.format(color.FgBlue, p.escapeLine(reason)))
+			}
+		}
+		return
+	}
+	source := "Transport.Proxy"
+	if fromEnvironment {
+		source = proxyEnvVar(req.URL.Scheme, getenv)
+	}
+	p.printf("* Using proxy: %s (from %s)\n", p.format(color.FgBlue, p.sanitizeURL(proxyURL)), source)
+}
+
+// isProxyFromEnvironment checks if the proxy chosen for a request is the one http.ProxyFromEnvironment chooses,
+// so that functions wrapping it are recognized too.
+func isProxyFromEnvironment(proxyURL *url.URL, req *http.Request) bool {
+	envURL, err := http.ProxyFromEnvironment(req)
+	if err != nil {
+		return false
+	}
+	if proxyURL == nil || envURL == nil {
+		return proxyURL == envURL
+	}
+	return proxyURL.String() == envURL.String()
+}
+
+// proxyGetenv reads the proxy environment variables only once, as http.ProxyFromEnvironment does,
+// so that the reasons printed agree with its decisions.
+var proxyGetenv = sync.OnceValue(func() func(string) string {
+	env := map[string]string{}
+	for _, name := range []string{"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "NO_PROXY", "no_proxy"} {
+		env[name] = os.Getenv(name)
+	}
+	return func(name string) string {
+		return env[name]
+	}
+})
+
+// proxyEnvVar returns the name of the environment variable http.ProxyFromEnvironment uses for a scheme.
+func proxyEnvVar(scheme string, getenv func(string) string) string {
+	names := []string{"HTTP_PROXY", "http_proxy"}
+	if scheme == "https" {
+		names = []string{"HTTPS_PROXY", "https_proxy"}
+	}
+	for _, name := range names {
+		if getenv(name) != "" {
+			return name
+		}
+	}
+	return "environment"
+}
+
+// noProxyReason explains why http.ProxyFromEnvironment doesn't use the proxy set for a URL, if any.
+func noProxyReason(u *url.URL, getenv func(string) string) string {
+	if proxyEnvVar(u.Scheme, getenv) == "environment" {
+		return "" // no proxy is set
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" {
+		return "requests to localhost aren't proxied"
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return "requests to loopback addresses aren't proxied"
+	}
+	name, noProxy := "NO_PROXY", getenv("NO_PROXY")
+	if noProxy == "" {
+		name, noProxy = "no_proxy", getenv("no_proxy")
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		if entry = strings.ToLower(strings.TrimSpace(entry)); entry != "" && matchNoProxy(u, host, ip, entry) {
+			return fmt.Sprintf("%s matches %s entry %s", host, name, entry)
+		}
+	}
+	return ""
+}
+
+// matchNoProxy matches a host against a NO_PROXY entry: *, an IP address, a CIDR range, or a domain name,
+// optionally with a port. Like http.ProxyFromEnvironment, example.com matches example.com and its subdomains,
+// but .example.com and *.example.com only match its subdomains.
+func matchNoProxy(u *url.URL, host string, ip net.IP, entry string) bool {
+	if entry == "*" {
+		return true
+	}
+	if _, network, err := net.ParseCIDR(entry); err == nil {
+		return ip != nil && network.Contains(ip)
+	}
+	if h, port, err := net.SplitHostPort(entry); err == nil {
+		if port != u.Port() && !(u.Port() == "" && port == defaultPort(u.Scheme)) {
+			return false
+		}
+		entry = h
+	}
+	if entryIP := net.ParseIP(entry); entryIP != nil {
+		return ip != nil && entryIP.Equal(ip)
+	}
+	if strings.HasPrefix(entry, "*.") {
+		entry = entry[1:]
+	}
+	if strings.HasPrefix(entry, ".") {
+		return strings.HasSuffix(host, entry)
+	}
+	return host == entry || strings.HasSuffix(host, "."+entry)
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// OnProxyConnectResponse prints the response of proxies to CONNECT requests, which tunnel requests to HTTPS servers.
+// To use it, set it as the OnProxyConnectResponse function of your http.Transport:
+//
+//	transport.OnProxyConnectResponse = logger.OnProxyConnectResponse
+//
+// It is called only when a new connection is established, and it's printed on its own, rather than along
+// the request that caused the connection to be established. The header sent to the proxy, such as
+// the Proxy-Authorization and Transport.ProxyConnectHeader fields, is printed sanitized.
+// Nothing is printed if the request was hidden with WithHide.
+func (l *Logger) OnProxyConnectResponse(ctx context.Context, proxyURL *url.URL, connectReq *http.Request, connectRes *http.Response) error {
+	// the context carries the values of the request that caused the connection to be established.
+	if hide := ctx.Value(contextHide{}); hide != nil {
+		return nil
+	}
+	p := newPrinter(l)
+	defer p.flush()
+	status := p.format(color.FgBlue, color.Bold, p.escapeLine(connectRes.Status))
+	if connectRes.StatusCode/100 != 2 {
+		status = p.format(color.FgRed, color.Bold, p.escapeLine(connectRes.Status))
+	}
+	p.printf("* Proxy CONNECT %s via %s: %s %s\n",
+		p.escapeLine(connectReq.Host),
+		p.sanitizeURL(proxyURL),
+		p.format(color.FgBlue, p.escapeLine(connectRes.Proto)),
+		status)
+	if len(connectReq.Header) != 0 {
+		p.println("* Proxy CONNECT header:")
+		p.printHeaders('*', connectReq.Header)
+	}
+	return nil
+}
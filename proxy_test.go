@@ -0,0 +1,89 @@
+package httpretty
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestIsProxyFromEnvironment(t *testing.T) {
+	t.Parallel()
+	// requests to localhost are never proxied by http.ProxyFromEnvironment.
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	if err != nil {
+		t.Fatalf("cannot create request: %v", err)
+	}
+	wrapped := func(req *http.Request) (*url.URL, error) {
+		return http.ProxyFromEnvironment(req)
+	}
+	proxyURL, err := wrapped(req)
+	if err != nil {
+		t.Fatalf("cannot get proxy: %v", err)
+	}
+	if !isProxyFromEnvironment(proxyURL, req) {
+		t.Error("expected a function wrapping http.ProxyFromEnvironment to be detected")
+	}
+	proxyURL, err = http.ProxyURL(&url.URL{Scheme: "http", Host: "proxy"})(req)
+	if err != nil {
+		t.Fatalf("cannot get proxy: %v", err)
+	}
+	if isProxyFromEnvironment(proxyURL, req) {
+		t.Error("expected http.ProxyURL not to be detected as http.ProxyFromEnvironment")
+	}
+}
+
+func TestProxyEnvVar(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{
+		"https_proxy": "http://proxy:3128",
+		"HTTP_PROXY":  "http://proxy:3128",
+	}
+	getenv := func(name string) string { return env[name] }
+	if got := proxyEnvVar("https", getenv); got != "https_proxy" {
+		t.Errorf("got %q, wanted https_proxy", got)
+	}
+	if got := proxyEnvVar("http", getenv); got != "HTTP_PROXY" {
+		t.Errorf("got %q, wanted HTTP_PROXY", got)
+	}
+	if got := proxyEnvVar("https", func(string) string { return "" }); got != "environment" {
+		t.Errorf("got %q, wanted environment", got)
+	}
+}
+
+func TestNoProxyReason(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{
+		"HTTPS_PROXY": "http://proxy:3128",
+		"no_proxy":    "internal.example.com, .corp, *.example.org, 10.0.0.0/8, 192.168.1.1, api.example.net:8443",
+	}
+	getenv := func(name string) string { return env[name] }
+	testCases := []struct {
+		url  string
+		want string
+	}{
+		{"https://internal.example.com/", "internal.example.com matches no_proxy entry internal.example.com"},
+		{"https://svc.internal.example.com/", "svc.internal.example.com matches no_proxy entry internal.example.com"},
+		{"https://wiki.corp/", "wiki.corp matches no_proxy entry .corp"},
+		{"https://corp/", ""},
+		{"https://docs.example.org/", "docs.example.org matches no_proxy entry *.example.org"},
+		{"https://example.org/", ""},
+		{"https://10.1.2.3/", "10.1.2.3 matches no_proxy entry 10.0.0.0/8"},
+		{"https://192.168.1.1/", "192.168.1.1 matches no_proxy entry 192.168.1.1"},
+		{"https://api.example.net:8443/", "api.example.net matches no_proxy entry api.example.net:8443"},
+		{"https://localhost:8080/", "requests to localhost aren't proxied"},
+		{"https://[::1]/", "requests to loopback addresses aren't proxied"},
+		{"https://api.example.net/", ""},
+		{"https://example.com/", ""},
+		{"https://notinternal.example.com/", ""},
+		{"http://internal.example.com/", ""}, // no HTTP_PROXY set
+	}
+	for _, tc := range testCases {
+		u, err := url.Parse(tc.url)
+		if err != nil {
+			t.Fatalf("cannot parse URL: %v", err)
+		}
+		if got := noProxyReason(u, getenv); got != tc.want {
+			t.Errorf("got noProxyReason(%q) = %q, wanted %q", tc.url, got, tc.want)
+		}
+	}
+}
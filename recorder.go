@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"sync"
+
+	"github.com/henvic/httpretty/internal/color"
 )
 
 type bodyCloser struct {
@@ -26,19 +29,133 @@ func newBodyReaderBuf(buf io.Reader, body io.ReadCloser) *bodyCloser {
 	}
 }
 
+// bodyStream feeds a body to the writer returned by StreamFormatter.NewStream.
+type bodyStream struct {
+	p      *printer
+	w      io.WriteCloser
+	failed bool
+	once   sync.Once
+
+	// onClose is called once the stream is closed, to print what comes after the body.
+	onClose func()
+}
+
+// Write never fails, as printing a body should never break reading or writing it.
+func (s *bodyStream) Write(b []byte) (int, error) {
+	if s.failed {
+		return len(b), nil
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			s.failed = true
+			s.p.printf("* panic while formatting body: %v\n", e)
+		}
+	}()
+	if _, err := s.w.Write(b); err != nil {
+		s.failed = true
+		s.p.printf("* body cannot be formatted: %v\n", s.p.format(color.FgRed, err.Error()))
+	}
+	return len(b), nil
+}
+
+// Close the stream and flush what was printed. It is safe to call it multiple times.
+func (s *bodyStream) Close() error {
+	s.once.Do(func() {
+		defer s.p.flush()
+		defer func() {
+			if e := recover(); e != nil {
+				s.p.printf("* panic while formatting body: %v\n", e)
+			}
+		}()
+		if err := s.w.Close(); err != nil && !s.failed {
+			s.p.printf("* body cannot be formatted: %v\n", s.p.format(color.FgRed, err.Error()))
+		}
+		if s.onClose != nil {
+			s.onClose()
+		}
+	})
+	return nil
+}
+
+// printerWriter prints each write as soon as the flushing strategy allows.
+type printerWriter struct {
+	p *printer
+}
+
+func (pw printerWriter) Write(b []byte) (int, error) {
+	pw.p.print(pw.p.escape(string(b)))
+	pw.p.maybeOnReady()
+	return len(b), nil
+}
+
+// streamBody prints a response body as it is read by the client.
+type streamBody struct {
+	io.ReadCloser
+	stream *bodyStream
+}
+
+func newStreamBody(body io.ReadCloser, stream *bodyStream) *streamBody {
+	return &streamBody{
+		ReadCloser: body,
+		stream:     stream,
+	}
+}
+
+func (sb *streamBody) Read(p []byte) (n int, err error) {
+	n, err = sb.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = sb.stream.Write(p[:n])
+	}
+	if err == io.EOF {
+		_ = sb.stream.Close()
+	}
+	return n, err
+}
+
+func (sb *streamBody) Close() error {
+	_ = sb.stream.Close()
+	return sb.ReadCloser.Close()
+}
+
 type responseRecorder struct {
 	http.ResponseWriter
 	statusCode      int
 	maxReadableBody int64
 	size            int64
 	buf             *bytes.Buffer
+
+	// bodyLimit is called on the first write to get the maximum size of the body to record.
+	bodyLimit func() int64
+
+	// keepHead records the first maxReadableBody bytes of longer bodies, rather than none, for printing a hexdump.
+	keepHead bool
+
+	// startStream is called on the first write to check if the body should be streamed.
+	startStream func() *bodyStream
+	stream      *bodyStream
 }
 
 // Write the data to the connection as part of an HTTP reply, and records it.
 func (rr *responseRecorder) Write(p []byte) (int, error) {
+	if rr.bodyLimit != nil {
+		rr.maxReadableBody = rr.bodyLimit()
+		rr.bodyLimit = nil
+	}
+	if rr.startStream != nil {
+		rr.stream = rr.startStream()
+		rr.startStream = nil
+	}
 	rr.size += int64(len(p))
+	if rr.stream != nil {
+		_, _ = rr.stream.Write(p)
+		return rr.ResponseWriter.Write(p)
+	}
 	if rr.maxReadableBody > 0 && rr.size > rr.maxReadableBody {
-		rr.buf = nil
+		if rr.keepHead && rr.buf != nil {
+			rr.buf.Write(p[:rr.maxReadableBody-int64(rr.buf.Len())])
+		} else {
+			rr.buf = nil
+		}
 		return rr.ResponseWriter.Write(p)
 	}
 	defer rr.buf.Write(p)
@@ -51,3 +168,15 @@ func (rr *responseRecorder) WriteHeader(statusCode int) {
 	rr.ResponseWriter.WriteHeader(statusCode)
 	rr.statusCode = statusCode
 }
+
+// Flush sends any buffered data to the client, if the underlying ResponseWriter supports it.
+func (rr *responseRecorder) Flush() {
+	if f, ok := rr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, so http.ResponseController can be used.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
@@ -0,0 +1,382 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/henvic/httpretty/internal/color"
+	"github.com/henvic/httpretty/internal/header"
+)
+
+// BodyRedaction rules for hiding sensitive values of request and response bodies, such as passwords and tokens.
+//
+// Values are replaced by a redaction block before the body is formatted. If a body cannot be parsed,
+// it is hidden rather than printed as is.
+type BodyRedaction struct {
+	// JSONPaths of values to redact from JSON bodies, such as $.user.password, $..access_token,
+	// $.cards[*].number, or $['api-key'].
+	JSONPaths []string
+
+	// Fields to redact from forms (application/x-www-form-urlencoded) and multipart forms (multipart/form-data).
+	Fields []string
+}
+
+// SetBodyRedaction allows you to redact values of bodies of a given media type, such as application/json.
+// Pass nil to remove the rules. This method is concurrency safe.
+func (l *Logger) SetBodyRedaction(mediatype string, r *BodyRedaction) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.bodyRedactions == nil {
+		l.bodyRedactions = map[string]*BodyRedaction{}
+	}
+	mediatype = strings.ToLower(mediatype)
+	if r == nil {
+		delete(l.bodyRedactions, mediatype)
+		return
+	}
+	l.bodyRedactions[mediatype] = r
+}
+
+func (l *Logger) getBodyRedaction(mediatype string) *BodyRedaction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bodyRedactions[mediatype]
+}
+
+// redactBody applies the redaction rules for the media type, if any. If the body cannot be redacted,
+// it prints why and returns false, so the body isn't printed.
+func (p *printer) redactBody(mediatype string, params map[string]string, body []byte) ([]byte, bool) {
+	r := p.logger.getBodyRedaction(mediatype)
+	if r == nil || len(body) == 0 {
+		return body, true
+	}
+	var err error
+	redact := p.logger.getRedactor()
+	switch {
+	case jsonTypeRE.MatchString(mediatype):
+		body, err = redactJSON(body, r.JSONPaths, redact)
+	case mediatype == "application/x-www-form-urlencoded":
+		body, err = redactForm(body, r.Fields, redact)
+	case mediatype == "multipart/form-data":
+		body, err = redactMultipart(body, params["boundary"], r.Fields, redact)
+	default:
+		err = fmt.Errorf("media type %s is not supported", mediatype)
+	}
+	if err != nil {
+		p.printf("* body hidden: cannot redact it: %s\n", p.format(color.FgRed, err.Error()))
+		return nil, false
+	}
+	return body, true
+}
+
+// jsonPathSegment is a step of a JSON path: a member name, an array index, or a wildcard.
+type jsonPathSegment struct {
+	descendant bool // matches at any depth, as in $..name
+	wildcard   bool
+	name       string
+	index      int // -1 unless matching an array index
+}
+
+// parseJSONPath parses the subset of JSONPath used to select values to redact:
+// member names ($.a.b or $['a']), array indexes ($.a[0]), wildcards ($.a[*] or $.a.*), and descendants ($..a).
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSON path %q: must start with $", path)
+	}
+	var segments []jsonPathSegment
+	s := path[1:]
+	for s != "" {
+		seg := jsonPathSegment{index: -1}
+		switch {
+		case strings.HasPrefix(s, ".."):
+			seg.descendant = true
+			s = s[2:]
+		case s[0] == '.':
+			s = s[1:]
+		case s[0] != '[':
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", path, s[0])
+		}
+		if strings.HasPrefix(s, "[") {
+			end := strings.IndexByte(s, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ]", path)
+			}
+			switch sel := s[1:end]; {
+			case sel == "*":
+				seg.wildcard = true
+			case len(sel) >= 2 && (sel[0] == '\'' || sel[0] == '"') && sel[len(sel)-1] == sel[0]:
+				seg.name = sel[1 : len(sel)-1]
+			default:
+				n, err := strconv.Atoi(sel)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: invalid selector [%s]", path, sel)
+				}
+				seg.index = n
+			}
+			s = s[end+1:]
+			segments = append(segments, seg)
+			continue
+		}
+		end := strings.IndexAny(s, ".[")
+		if end == -1 {
+			end = len(s)
+		}
+		seg.name, s = s[:end], s[end:]
+		switch seg.name {
+		case "":
+			return nil, fmt.Errorf("invalid JSON path %q: empty member name", path)
+		case "*":
+			seg.wildcard, seg.name = true, ""
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid JSON path %q: selects the whole document", path)
+	}
+	return segments, nil
+}
+
+// jsonPathElement is a member name or an array index on the path to a value.
+type jsonPathElement struct {
+	name  string
+	index int // -1 for member names
+}
+
+func (seg jsonPathSegment) matches(e jsonPathElement) bool {
+	switch {
+	case seg.wildcard:
+		return true
+	case seg.index != -1:
+		return e.index == seg.index
+	}
+	return e.index == -1 && e.name == seg.name
+}
+
+func matchJSONPath(segments []jsonPathSegment, path []jsonPathElement) bool {
+	if len(segments) == 0 {
+		return len(path) == 0
+	}
+	seg := segments[0]
+	if !seg.descendant {
+		return len(path) != 0 && seg.matches(path[0]) && matchJSONPath(segments[1:], path[1:])
+	}
+	for i := range path {
+		if seg.matches(path[i]) && matchJSONPath(segments[1:], path[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFrame is an object or array being read.
+type jsonFrame struct {
+	object    bool
+	expectKey bool
+	element   jsonPathElement
+}
+
+// redactJSON replaces the values selected by the paths, keeping the rest of the document as is.
+func redactJSON(src []byte, paths []string, redact header.Redactor) ([]byte, error) {
+	var compiled [][]jsonPathSegment
+	for _, path := range paths {
+		segments, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, segments)
+	}
+	if !json.Valid(src) {
+		// report the error of json.Unmarshal, as JSONFormatter does.
+		if err := json.Unmarshal(src, &json.RawMessage{}); err != nil {
+			return nil, err
+		}
+	}
+	type span struct{ start, end int }
+	var (
+		spans  []span
+		frames []jsonFrame
+		dec    = json.NewDecoder(bytes.NewReader(src))
+	)
+	// done moves on to the next member or element of the enclosing object or array.
+	done := func() {
+		if len(frames) == 0 {
+			return
+		}
+		f := &frames[len(frames)-1]
+		if f.object {
+			f.expectKey = true
+		} else {
+			f.element.index++
+		}
+	}
+	for {
+		before := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) && len(frames) != 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(frames) != 0 {
+			if f := &frames[len(frames)-1]; f.object && f.expectKey {
+				if key, ok := tok.(string); ok {
+					f.element = jsonPathElement{name: key, index: -1}
+					f.expectKey = false
+					continue
+				}
+			}
+		}
+		if d, ok := tok.(json.Delim); ok && (d == '}' || d == ']') {
+			frames = frames[:len(frames)-1]
+			done()
+			continue
+		}
+		path := make([]jsonPathElement, len(frames))
+		for i, f := range frames {
+			path[i] = f.element
+		}
+		if matchAnyJSONPath(compiled, path) {
+			if d, ok := tok.(json.Delim); ok && (d == '{' || d == '[') {
+				if err := skipJSONValue(dec); err != nil {
+					return nil, err
+				}
+			}
+			start := before + len(src[before:]) - len(bytes.TrimLeft(src[before:], " \t\r\n:,"))
+			spans = append(spans, span{start, int(dec.InputOffset())})
+			done()
+			continue
+		}
+		switch tok {
+		case json.Delim('{'):
+			frames = append(frames, jsonFrame{object: true, expectKey: true})
+		case json.Delim('['):
+			frames = append(frames, jsonFrame{element: jsonPathElement{index: 0}})
+		default:
+			done()
+		}
+	}
+	if len(spans) == 0 {
+		return src, nil
+	}
+	var buf bytes.Buffer
+	last := 0
+	for _, s := range spans {
+		buf.Write(src[last:s.start])
+		value := src[s.start:s.end]
+		// redact the content of strings, so the same value is redacted the same way in JSON and other bodies.
+		var str string
+		if err := json.Unmarshal(value, &str); err != nil {
+			str = string(value)
+		}
+		if str == "" {
+			buf.Write(value)
+		} else {
+			buf.WriteString(strconv.Quote(redact(str)))
+		}
+		last = s.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes(), nil
+}
+
+func matchAnyJSONPath(paths [][]jsonPathSegment, path []jsonPathElement) bool {
+	for _, segments := range paths {
+		if matchJSONPath(segments, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipJSONValue reads the tokens of an object or array, after its opening delimiter.
+func skipJSONValue(dec *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+// redactForm replaces the values of the form fields, keeping the order of the fields.
+func redactForm(src []byte, fields []string, redact header.Redactor) ([]byte, error) {
+	if _, err := url.ParseQuery(string(src)); err != nil {
+		return nil, err
+	}
+	pairs := strings.Split(string(src), "&")
+	for i, pair := range pairs {
+		k, v, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(k); err == nil && slices.Contains(fields, name) {
+			value, err := url.QueryUnescape(v)
+			if err != nil {
+				value = v
+			}
+			pairs[i] = k + "=" + redact(value)
+		}
+	}
+	return []byte(strings.Join(pairs, "&")), nil
+}
+
+// redactMultipart replaces the content of the parts of the multipart form fields.
+func redactMultipart(src []byte, boundary string, fields []string, redact header.Redactor) ([]byte, error) {
+	if boundary == "" {
+		return nil, errors.New("multipart body has no boundary")
+	}
+	var (
+		r   = multipart.NewReader(bytes.NewReader(src), boundary)
+		buf bytes.Buffer
+		w   = multipart.NewWriter(&buf)
+	)
+	if err := w.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := r.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(fields, part.FormName()) {
+			content = []byte(redact(string(content)))
+		}
+		pw, err := w.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write(content); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
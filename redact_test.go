@@ -0,0 +1,142 @@
+package httpretty
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/henvic/httpretty/internal/header"
+)
+
+func TestRedactJSON(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc  string
+		paths []string
+		src   string
+		want  string
+	}{
+		{
+			desc:  "member",
+			paths: []string{"$.user.password"},
+			src:   `{"user": {"name": "gopher", "password": "hunter2"}, "password": "kept"}`,
+			want:  `{"user": {"name": "gopher", "password": "████████████████████"}, "password": "kept"}`,
+		},
+		{
+			desc:  "descendants",
+			paths: []string{"$..access_token"},
+			src:   `{"access_token":"a","nested":[{"access_token":"b"},{"access_token":""}]}`,
+			want:  `{"access_token":"████████████████████","nested":[{"access_token":"████████████████████"},{"access_token":""}]}`,
+		},
+		{
+			desc:  "wildcard and index",
+			paths: []string{"$.cards[*].number", "$.cards[0].cvc"},
+			src:   `{"cards":[{"number":4111111111111111,"cvc":"123"},{"number":5500000000000004,"cvc":"456"}]}`,
+			want:  `{"cards":[{"number":"████████████████████","cvc":"████████████████████"},{"number":"████████████████████","cvc":"456"}]}`,
+		},
+		{
+			desc:  "objects",
+			paths: []string{"$['api-key']", "$.secrets"},
+			src:   `{"api-key":"k","secrets":{"a":[1,2,{"b":null}]},"ok":true}`,
+			want:  `{"api-key":"████████████████████","secrets":"████████████████████","ok":true}`,
+		},
+		{
+			desc:  "root array",
+			paths: []string{"$[*].password"},
+			src:   "[\n  {\"password\": \"x\"},\n  {\"password\" : \"y\"}\n]",
+			want:  "[\n  {\"password\": \"████████████████████\"},\n  {\"password\" : \"████████████████████\"}\n]",
+		},
+		{
+			desc:  "no matches",
+			paths: []string{"$.password"},
+			src:   `{"user":{"password":"kept"}}`,
+			want:  `{"user":{"password":"kept"}}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+			got, err := redactJSON([]byte(tc.src), tc.paths, header.Block)
+			if err != nil {
+				t.Errorf("got error = %v, wanted nil", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("got %s, wanted %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRedactJSONErrors(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		path string
+		src  string
+		want string
+	}{
+		{"password", `{}`, `invalid JSON path "password": must start with $`},
+		{"$", `{}`, `invalid JSON path "$": selects the whole document`},
+		{"$.a[", `{}`, `invalid JSON path "$.a[": missing ]`},
+		{"$.a[x]", `{}`, `invalid JSON path "$.a[x]": invalid selector [x]`},
+		{"$.a..", `{}`, `invalid JSON path "$.a..": empty member name`},
+		{"$.password", `{"password": "hunter2"`, "unexpected end of JSON input"},
+		{"$.password", `{"password": hunter2}`, "invalid character 'h' looking for beginning of value"},
+	}
+	for _, tc := range testCases {
+		if _, err := redactJSON([]byte(tc.src), []string{tc.path}, header.Block); err == nil || err.Error() != tc.want {
+			t.Errorf("got redactJSON(%q) error = %v, wanted %v", tc.src, err, tc.want)
+		}
+	}
+}
+
+func TestRedactForm(t *testing.T) {
+	t.Parallel()
+	got, err := redactForm([]byte("user=gopher&password=hunter%32&card%5Bnumber%5D=4111&empty=&password=2"), []string{"password", "card[number]", "empty"}, header.Block)
+	if err != nil {
+		t.Errorf("got error = %v, wanted nil", err)
+	}
+	if want := "user=gopher&password=████████████████████&card%5Bnumber%5D=████████████████████&empty=&password=████████████████████"; string(got) != want {
+		t.Errorf("got %s, wanted %s", got, want)
+	}
+	if _, err := redactForm([]byte("password=%zz"), []string{"password"}, header.Block); err == nil {
+		t.Error("expected error redacting invalid form")
+	}
+}
+
+func TestRedactMultipart(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary("boundary"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteField("user", "gopher"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteField("password", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := redactMultipart(buf.Bytes(), "boundary", []string{"password"}, header.Block)
+	if err != nil {
+		t.Errorf("got error = %v, wanted nil", err)
+	}
+	want := "--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"user\"\r\n\r\n" +
+		"gopher\r\n" +
+		"--boundary\r\n" +
+		"Content-Disposition: form-data; name=\"password\"\r\n\r\n" +
+		"████████████████████\r\n" +
+		"--boundary--\r\n"
+	if string(got) != want {
+		t.Errorf("got %q, wanted %q", got, want)
+	}
+	if _, err := redactMultipart(buf.Bytes(), "", []string{"password"}, header.Block); err == nil || err.Error() != "multipart body has no boundary" {
+		t.Errorf("got error = %v, wanted missing boundary", err)
+	}
+	if _, err := redactMultipart([]byte("--boundary\r\nbroken"), "boundary", []string{"password"}, header.Block); err == nil {
+		t.Error("expected error redacting truncated multipart body")
+	}
+}
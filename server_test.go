@@ -7,6 +7,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"mime"
 	"mime/multipart"
 	"net"
@@ -1275,3 +1276,716 @@ func newServerClient() *http.Client {
 		Transport: newTransport(),
 	}
 }
+
+func TestIncomingStreamNDJSON(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo:    true,
+		ResponseHeader:     true,
+		ResponseBody:       true,
+		StreamResponseBody: true,
+		Formatters:         []Formatter{&NDJSONFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(ndjsonHandler{}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		resp, err := newServerClient().Get(ts.URL)
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingStreamGRPC(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo:    true,
+		ResponseHeader:     true,
+		ResponseBody:       true,
+		StreamResponseBody: true,
+		Formatters:         []Formatter{&GRPCFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(grpcStreamHandler{}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		resp, err := newServerClient().Post(ts.URL, "application/grpc+proto", bytes.NewReader(grpcFrame(0, nil)))
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingStreamSSE(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo:    true,
+		ResponseHeader:     true,
+		ResponseBody:       true,
+		StreamResponseBody: true,
+		Formatters: []Formatter{
+			&SSEFormatter{
+				Formatters:       []Formatter{&JSONFormatter{}},
+				CollapseComments: true,
+			},
+		},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	next := make(chan struct{})
+	is := inspect(logger.Middleware(sseHandler{next}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		resp, err := newServerClient().Get(ts.URL)
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			close(next)
+			return
+		}
+		defer resp.Body.Close()
+		first := make([]byte, len("event: greeting\ndata: {\"msg\":\"hello\"}\n\n"))
+		if _, err := io.ReadFull(resp.Body, first); err != nil {
+			t.Errorf("cannot read first event: %v", err)
+		}
+		close(next)
+		_, _ = io.Copy(io.Discard, resp.Body)
+	}()
+	<-next
+	logger.mu.Lock() // the handler might be still writing
+	got := buf.String()
+	logger.mu.Unlock()
+	if !strings.Contains(got, "event: greeting\n") {
+		t.Errorf("expected the first event to be logged as soon as it was written, got %s", got)
+	}
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+type jsonRPCHandler struct{}
+
+func (h jsonRPCHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header()["Date"] = nil
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `[{"jsonrpc":"2.0","result":"0x10","id":2},{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params"},"id":1}]`)
+}
+
+func TestIncomingJSONRPC(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		RequestBody:     true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		Formatters:      []Formatter{&JSONRPCFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(jsonRPCHandler{}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		body := `[{"jsonrpc":"2.0","method":"eth_getBalance","params":["0x0"],"id":1},{"jsonrpc":"2.0","method":"eth_blockNumber","id":2}]`
+		resp, err := newServerClient().Post(ts.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+type grpcWebHandler struct{}
+
+func (h grpcWebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/grpc-web+proto")
+	_, _ = w.Write(grpcFrame(0, protoEncoder{}.varint(1, 7)))
+	_, _ = w.Write(grpcFrame(grpcWebTrailerFlag, []byte("grpc-status: 0\r\n")))
+}
+
+func TestIncomingGRPCWeb(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		RequestBody:     true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		Formatters:      []Formatter{&GRPCFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(grpcWebHandler{}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		body := grpcFrame(0, protoEncoder{}.string(1, "ping"))
+		resp, err := newServerClient().Post(ts.URL+"/echo.v1.EchoService/Echo", "application/grpc-web+proto", bytes.NewReader(body))
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingGzip(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		RequestBody:     true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		Formatters:      []Formatter{&JSONFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(gzipHandler{}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		req, err := http.NewRequest(http.MethodPost, ts.URL, bytes.NewReader(gzipBytes(`{"upload":"compressed"}`)))
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := newServerClient().Do(req)
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingGzipTooLong(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		RequestBody:     true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		MaxRequestBody:  4096,
+		MaxResponseBody: 4096,
+		Formatters:      []Formatter{&JSONFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(gzipBombHandler{}), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		body := gzipBytes(`{"padding":"` + strings.Repeat("a", 1<<20) + `"}`)
+		req, err := http.NewRequest(http.MethodPost, ts.URL, bytes.NewReader(body))
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := newServerClient().Do(req)
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingHexDumpHead(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		HexDump:         16,
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		w.Header().Set("Content-Type", "video/mp4")
+		_, _ = w.Write(make([]byte, 1<<20))
+	})), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		resp, err := newServerClient().Get(ts.URL)
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		testBody(t, resp.Body, make([]byte, 1<<20))
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingHexDump(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		RequestBody:     true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		HexDump:         8,
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		w.Header().Set("Content-Type", "application/octet-stream")
+		fmt.Fprint(w, "\x00\x01\x02\x03binary\xff")
+	})), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		req, err := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader("GIF89a\x01\x00\x01\x00\x00\xff\x00,"))
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+			return
+		}
+		req.Header.Add("Content-Type", "image/gif")
+		resp, err := newServerClient().Do(req)
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingCSV(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		SkipRequestInfo: true,
+		ResponseHeader:  true,
+		ResponseBody:    true,
+		Formatters:      []Formatter{&CSVFormatter{MaxRows: 2, Width: 40}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		fmt.Fprint(w, "day,visits,top page\n2024-03-01,120,/docs/getting-started/installation\n2024-03-02,98,/\n2024-03-03,143,/blog\n")
+	})), 1)
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	go func() {
+		resp, err := newServerClient().Get(ts.URL + "/report.csv")
+		if err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+			return
+		}
+		resp.Body.Close()
+	}()
+	is.Wait()
+	want := golden(t.Name())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+type loginHandler struct{}
+
+func (h loginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, err := io.Copy(io.Discard, r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"user":{"name":"gopher","password":"hunter2"},"access_token":"2YotnFZFEjr1zCsicMWpAA","expires_in":3600}`)
+}
+
+func TestIncomingBodyRedaction(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+		Formatters: []Formatter{
+			&JSONFormatter{},
+		},
+	}
+	logger.SetBodyRedaction("application/x-www-form-urlencoded", &BodyRedaction{
+		Fields: []string{"password"},
+	})
+	logger.SetBodyRedaction("application/json", &BodyRedaction{
+		JSONPaths: []string{"$.user.password", "$..access_token"},
+	})
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(loginHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/login", ts.URL)
+	go func() {
+		client := newServerClient()
+		form := url.Values{}
+		form.Add("user", "gopher")
+		form.Add("password", "hunter2")
+		req, err := http.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingBodyRedactionFailClosed(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+		Formatters: []Formatter{
+			&JSONFormatter{},
+		},
+	}
+	logger.SetBodyRedaction("application/json", &BodyRedaction{
+		JSONPaths: []string{"$.password"},
+	})
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(badJSONHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/json", ts.URL)
+	go func() {
+		client := newServerClient()
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Add("User-Agent", "Robot/0.1 crawler@example.com")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+type profileHandler struct{}
+
+func (h profileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprint(w, `{"name":"Jane Doe","emails":["jane@example.com","jane.doe@example.org"],"phone":"+14155552671","card":"4111 1111 1111 1111","orders":4111111111111112}`)
+}
+
+func TestIncomingRedactPII(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+		RedactPII:      PIIAll,
+		Formatters: []Formatter{
+			&JSONFormatter{},
+		},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(profileHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/profile", ts.URL)
+	go func() {
+		client := newServerClient()
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Add("User-Agent", "Robot/0.1 crawler@example.com")
+		req.Header.Add("X-Forwarded-For", "203.0.113.7, 2001:db8::1")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingHashRedactions(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+		HashRedactions: true,
+		Formatters: []Formatter{
+			&JSONFormatter{},
+		},
+	}
+	logger.SetRedactionKey([]byte("test key"))
+	logger.SetHeaderSanitizer("X-Api-Key", logger.RedactHeaderValue)
+	logger.SetBodyRedaction("application/x-www-form-urlencoded", &BodyRedaction{
+		Fields: []string{"password"},
+	})
+	logger.SetBodyRedaction("application/json", &BodyRedaction{
+		JSONPaths: []string{"$.user.password", "$..access_token"},
+	})
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(loginHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/login", ts.URL)
+	go func() {
+		client := newServerClient()
+		form := url.Values{}
+		form.Add("user", "gopher")
+		form.Add("password", "hunter2")
+		req, err := http.NewRequest(http.MethodPost, uri, strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Cookie", "session=2YotnFZFEjr1zCsicMWpAA")
+		req.Header.Set("X-Api-Key", "2YotnFZFEjr1zCsicMWpAA")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+type spoofHandler struct{}
+
+func (h spoofHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"invoice_\u202efdp.exe\"")
+	fmt.Fprint(w, "\x1b]8;;https://evil.example.com\x1b\\click here\x1b]8;;\x1b\\\r< HTTP/1.1 200 OK\n")
+}
+
+func TestIncomingEscape(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(spoofHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/invoice", ts.URL)
+	go func() {
+		client := newServerClient()
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Add("User-Agent", "Robot/0.1 crawler@example.com")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+type spoofProblemHandler struct{}
+
+func (h spoofProblemHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(http.StatusForbidden)
+	fmt.Fprint(w, `{"title":"\u001b]0;pwn\u0007Forbidden","detail":"\u001b[8mhidden\u001b[0m\r< HTTP/1.1 200 OK"}`)
+}
+
+func TestIncomingEscapeColors(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		ResponseHeader: true,
+		ResponseBody:   true,
+		Colors:         true,
+		Formatters:     []Formatter{&ProblemFormatter{}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(spoofProblemHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/problem", ts.URL)
+	go func() {
+		client := newServerClient()
+		if _, err := client.Get(uri); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	got := buf.String()
+	for _, unsafe := range []string{"\x1b]", "\x07", "\x1b[8m", "\r"} {
+		if strings.Contains(got, unsafe) {
+			t.Errorf("logged HTTP request %q; want %q escaped", got, unsafe)
+		}
+	}
+	want := "\x1b[31;1m\\x1b]0;pwn\\aForbidden\x1b[0m\ndetail: \\x1b[8mhidden\\x1b[0m\\r< HTTP/1.1 200 OK\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("logged HTTP request %q; want it to contain %q", got, want)
+	}
+}
+
+type zipHandler struct{}
+
+func (h zipHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/zip")
+	fmt.Fprint(w, "not really a zip file")
+}
+
+func TestIncomingBinaryResponseContentType(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(zipHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/download", ts.URL)
+	go func() {
+		client := newServerClient()
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Add("User-Agent", "Robot/0.1 crawler@example.com")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr())
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
+
+func TestIncomingArchiveTooLarge(t *testing.T) {
+	t.Parallel()
+	logger := &Logger{
+		RequestHeader:  true,
+		RequestBody:    true,
+		ResponseHeader: true,
+		ResponseBody:   true,
+		Formatters:     []Formatter{&ArchiveFormatter{MaxSize: 50}},
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	is := inspect(logger.Middleware(archiveHandler{}), 1)
+
+	ts := httptest.NewServer(is)
+	defer ts.Close()
+	uri := fmt.Sprintf("%s/download", ts.URL)
+	go func() {
+		client := newServerClient()
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			t.Errorf("cannot create request: %v", err)
+		}
+		req.Header.Add("User-Agent", "Robot/0.1 crawler@example.com")
+		if _, err = client.Do(req); err != nil {
+			t.Errorf("cannot connect to the server: %v", err)
+		}
+	}()
+	is.Wait()
+	want := fmt.Sprintf(golden(t.Name()), uri, is.req.RemoteAddr, ts.Listener.Addr(), len(testTarGz()))
+	if got := buf.String(); got != want {
+		t.Errorf("logged HTTP request %s; want %s", got, want)
+	}
+}
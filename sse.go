@@ -0,0 +1,262 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SSEFormatter formats Server-Sent Events (text/event-stream) bodies.
+//
+// Each event is printed as a block with its event, id, retry, and data fields.
+// The data field is formatted with the first of Formatters matching its media type,
+// which is application/json for valid JSON documents, and text/plain otherwise.
+// It is a StreamFormatter, so events can be printed as they arrive (see Logger.StreamResponseBody).
+type SSEFormatter struct {
+	// Formatters used to format the data field of events, such as JSONFormatter.
+	Formatters []Formatter
+
+	// CollapseComments prints a single line counting consecutive comments, such as keep-alive pings,
+	// rather than printing each of them.
+	CollapseComments bool
+}
+
+// Match text/event-stream media type.
+func (s *SSEFormatter) Match(mediatype string) bool {
+	return mediatype == "text/event-stream"
+}
+
+// Format Server-Sent Events.
+func (s *SSEFormatter) Format(w io.Writer, src []byte) error {
+	var buf bytes.Buffer
+	stream := s.NewStream(&buf)
+	if _, err := stream.Write(src); err != nil {
+		return err
+	}
+	if err := stream.Close(); err != nil {
+		return err
+	}
+	// the body is printed with a trailing newline already.
+	_, err := w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// NewStream returns a writer that formats each event as soon as it is dispatched.
+func (s *SSEFormatter) NewStream(w io.Writer) io.WriteCloser {
+	return &sseStream{
+		f: s,
+		w: w,
+	}
+}
+
+// newLimitedStream returns a stream skipping events longer than max bytes, rather than buffering them.
+func (s *SSEFormatter) newLimitedStream(w io.Writer, max int64) io.WriteCloser {
+	return &sseStream{
+		f:   s,
+		w:   w,
+		max: max,
+	}
+}
+
+type sseEvent struct {
+	event, id, retry string
+	data             []string
+	unknown          []string
+}
+
+type sseStream struct {
+	f        *SSEFormatter
+	w        io.Writer
+	buf      []byte // incomplete line
+	started  bool
+	event    sseEvent
+	comments []string
+
+	max          int64 // maximum length of the lines of an event, if set
+	size         int   // length of the lines of the current event
+	skipping     bool  // the rest of the current event is skipped
+	skippingLine bool  // the rest of the current line is skipped
+}
+
+func (s *sseStream) Write(p []byte) (int, error) {
+	n := len(p)
+	if s.skippingLine {
+		i := bytes.IndexByte(p, '\n')
+		if i == -1 {
+			return n, nil
+		}
+		s.skippingLine = false
+		p = p[i+1:]
+	}
+	s.buf = append(s.buf, p...)
+	if !s.started {
+		if len(s.buf) < 3 && bytes.HasPrefix([]byte("\xEF\xBB\xBF"), s.buf) {
+			return len(p), nil
+		}
+		s.buf = bytes.TrimPrefix(s.buf, []byte("\xEF\xBB\xBF"))
+		s.started = true
+	}
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i == -1 {
+			break
+		}
+		line := strings.TrimSuffix(string(s.buf[:i]), "\r")
+		s.buf = s.buf[i+1:]
+		if s.skipping {
+			s.skipping = line != ""
+			continue
+		}
+		if err := s.parseLine(line); err != nil {
+			return 0, err
+		}
+		s.size += len(line) + 1
+		if line == "" {
+			s.size = 0 // the event was dispatched.
+		}
+		if s.max > 0 && int64(s.size) > s.max {
+			if err := s.skipEvent(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if s.max > 0 && int64(s.size+len(s.buf)) > s.max {
+		// drop the incomplete line, and skip the rest of it.
+		s.buf = nil
+		s.skippingLine = true
+		if !s.skipping {
+			if err := s.skipEvent(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// skipEvent drops the current event, as it is too long, and skips the rest of it.
+func (s *sseStream) skipEvent() error {
+	s.event = sseEvent{}
+	s.size = 0
+	s.skipping = true
+	if err := s.writeComments(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(s.w, "event is too long, skipping (longer than %d bytes)\n\n", s.max)
+	return err
+}
+
+// Close prints any incomplete event left.
+func (s *sseStream) Close() error {
+	if len(s.buf) != 0 && !s.skipping {
+		if err := s.parseLine(strings.TrimSuffix(string(s.buf), "\r")); err != nil {
+			return err
+		}
+		s.buf = nil
+	}
+	if err := s.dispatch(); err != nil {
+		return err
+	}
+	return s.writeComments()
+}
+
+func (s *sseStream) parseLine(line string) error {
+	if line == "" {
+		return s.dispatch()
+	}
+	field, value, _ := strings.Cut(line, ":")
+	value = strings.TrimPrefix(value, " ")
+	switch field {
+	case "":
+		s.comments = append(s.comments, value)
+		if !s.f.CollapseComments {
+			return s.writeComments()
+		}
+	case "event":
+		s.event.event = value
+	case "id":
+		s.event.id = value
+	case "retry":
+		s.event.retry = value
+	case "data":
+		s.event.data = append(s.event.data, value)
+	default:
+		// unknown fields are ignored by clients, but should be visible when debugging.
+		s.event.unknown = append(s.event.unknown, line)
+	}
+	return nil
+}
+
+func (s *sseStream) writeComments() error {
+	var buf bytes.Buffer
+	switch n := len(s.comments); {
+	case n == 0:
+		return nil
+	case s.f.CollapseComments && n == 1:
+		buf.WriteString(": 1 comment\n\n")
+	case s.f.CollapseComments:
+		fmt.Fprintf(&buf, ": %d comments\n\n", n)
+	default:
+		for _, c := range s.comments {
+			fmt.Fprintf(&buf, ": %s\n", c)
+		}
+		buf.WriteByte('\n')
+	}
+	s.comments = nil
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+func (s *sseStream) dispatch() error {
+	e := s.event
+	if e.event == "" && e.id == "" && e.retry == "" && len(e.data) == 0 && len(e.unknown) == 0 {
+		return nil
+	}
+	s.event = sseEvent{}
+	if err := s.writeComments(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if e.event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", e.event)
+	}
+	if e.id != "" {
+		fmt.Fprintf(&buf, "id: %s\n", e.id)
+	}
+	if e.retry != "" {
+		fmt.Fprintf(&buf, "retry: %s\n", e.retry)
+	}
+	for _, line := range e.unknown {
+		fmt.Fprintf(&buf, "%s\n", line)
+	}
+	if len(e.data) != 0 {
+		data := s.formatData(strings.Join(e.data, "\n"))
+		if strings.Contains(data, "\n") {
+			fmt.Fprintf(&buf, "data:\n%s\n", data)
+		} else {
+			fmt.Fprintf(&buf, "data: %s\n", data)
+		}
+	}
+	buf.WriteByte('\n')
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+func (s *sseStream) formatData(data string) string {
+	mediatype := "text/plain"
+	if json.Valid([]byte(data)) {
+		mediatype = "application/json"
+	}
+	for _, f := range s.f.Formatters {
+		if !f.Match(mediatype) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := f.Format(&buf, []byte(data)); err != nil {
+			return data
+		}
+		return buf.String()
+	}
+	return data
+}
@@ -0,0 +1,101 @@
+package httpretty
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSSEFormatter(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		desc string
+		f    *SSEFormatter
+		src  string
+		want string
+	}{
+		{
+			desc: "events",
+			f:    &SSEFormatter{Formatters: []Formatter{&JSONFormatter{}}},
+			src:  "\xEF\xBB\xBFevent: update\r\nid: 1\r\ndata: {\"a\":1}\r\n\r\n: ping\n\nretry: 3000\ndata: hello\ndata: world\n\ndata: last",
+			want: `event: update
+id: 1
+data:
+{
+    "a": 1
+}
+
+: ping
+
+retry: 3000
+data:
+hello
+world
+
+data: last
+`,
+		},
+		{
+			desc: "collapsed comments",
+			f:    &SSEFormatter{CollapseComments: true},
+			src:  ": ping\n\n: ping\n\n:\n\ndata: {\"a\":1}\n\n: ping\n\n",
+			want: `: 3 comments
+
+data: {"a":1}
+
+: 1 comment
+`,
+		},
+		{
+			desc: "unknown field",
+			f:    &SSEFormatter{},
+			src:  "foo: bar\ndata: x\n\n",
+			want: "foo: bar\ndata: x\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			if !tc.f.Match("text/event-stream") {
+				t.Error("expected SSEFormatter to match text/event-stream")
+			}
+			var buf bytes.Buffer
+			if err := tc.f.Format(&buf, []byte(tc.src)); err != nil {
+				t.Errorf("got format error = %v, wanted nil", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("got formatted body %q, wanted %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSSEFormatterLimitedStream(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	s := (&SSEFormatter{}).newLimitedStream(&buf, 20)
+	chunks := []string{
+		"data: short\n\n",
+		"data: 0123456789\ndata: 0123456789\ndata: more\n\n", // too many lines
+		"data: 0123456789", "0123456789", "0123456789\n", "id: 2\n\n", // a line too long
+		"data: last\n\n",
+	}
+	for _, chunk := range chunks {
+		if _, err := s.Write([]byte(chunk)); err != nil {
+			t.Errorf("got stream error = %v, wanted nil", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("got stream error = %v, wanted nil", err)
+	}
+	want := `data: short
+
+event is too long, skipping (longer than 20 bytes)
+
+event is too long, skipping (longer than 20 bytes)
+
+data: last
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got formatted body %q, wanted %q", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+package httpretty
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// treeMaxDepth limits the nesting of arrays and maps decoded by the binary JSON-like formatters.
+const treeMaxDepth = 64
+
+// treeNode is a value decoded by the MessagePack and CBOR formatters, printed as a JSON-like tree.
+type treeNode struct {
+	kind  treeKind
+	value string     // text of scalars
+	items []treeNode // elements of arrays, or alternating keys and values of maps
+	note  string     // type annotation, such as "tag 1" or "binary, 4 bytes"
+	typ   string     // type of a scalar, used to annotate non-string map keys
+}
+
+type treeKind int
+
+const (
+	treeScalar treeKind = iota
+	treeArray
+	treeMap
+)
+
+func treeString(s string) treeNode {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return treeNode{value: string(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), typ: "string"}
+}
+
+func treeInt(v int64) treeNode {
+	return treeNode{value: strconv.FormatInt(v, 10), typ: "integer"}
+}
+
+func treeUint(v uint64) treeNode {
+	return treeNode{value: strconv.FormatUint(v, 10), typ: "integer"}
+}
+
+func treeFloat(v float64, bits int) treeNode {
+	var s string
+	switch {
+	case math.IsNaN(v):
+		s = "NaN"
+	case math.IsInf(v, 1):
+		s = "Infinity"
+	case math.IsInf(v, -1):
+		s = "-Infinity"
+	default:
+		s = strconv.FormatFloat(v, 'g', -1, bits)
+	}
+	return treeNode{value: s, typ: "float"}
+}
+
+func treeBool(v bool) treeNode {
+	return treeNode{value: strconv.FormatBool(v), typ: "boolean"}
+}
+
+func treeNull() treeNode {
+	return treeNode{value: "null", typ: "null"}
+}
+
+// treeBinaryLimit limits how many bytes of binary data are printed.
+const treeBinaryLimit = 64
+
+func treeBinary(b []byte, note string) treeNode {
+	s := "0x" + hex.EncodeToString(b)
+	if len(b) > treeBinaryLimit {
+		s = "0x" + hex.EncodeToString(b[:treeBinaryLimit]) + "..."
+	}
+	if len(b) == 0 {
+		s = `""`
+	}
+	size := fmt.Sprintf("%d bytes", len(b))
+	if len(b) == 1 {
+		size = "1 byte"
+	}
+	return treeNode{value: s, typ: "binary", note: note + ", " + size}
+}
+
+func treeTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// writeTree writes a node with the indentation of JSONFormatter.
+func writeTree(buf *bytes.Buffer, n treeNode, indent string) {
+	switch n.kind {
+	case treeArray, treeMap:
+		open, end := "[", "]"
+		step := 1
+		if n.kind == treeMap {
+			open, end, step = "{", "}", 2
+		}
+		buf.WriteString(open)
+		for i := 0; i < len(n.items); i += step {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("\n" + indent + "    ")
+			if n.kind == treeMap {
+				writeTreeKey(buf, n.items[i])
+				buf.WriteString(": ")
+				writeTree(buf, n.items[i+1], indent+"    ")
+				continue
+			}
+			writeTree(buf, n.items[i], indent+"    ")
+		}
+		if len(n.items) != 0 {
+			buf.WriteString("\n" + indent)
+		}
+		buf.WriteString(end)
+	default:
+		buf.WriteString(n.value)
+	}
+	if n.note != "" {
+		fmt.Fprintf(buf, " (%s)", n.note)
+	}
+}
+
+// writeTreeKey writes a map key on a single line, annotating keys that aren't strings.
+func writeTreeKey(buf *bytes.Buffer, n treeNode) {
+	if n.kind == treeScalar && n.typ == "string" && n.note == "" {
+		buf.WriteString(n.value)
+		return
+	}
+	var key bytes.Buffer
+	writeTree(&key, n, "")
+	if n.kind != treeScalar {
+		// compact nested keys
+		var compact bytes.Buffer
+		for _, line := range bytes.Split(key.Bytes(), []byte("\n")) {
+			compact.Write(bytes.TrimLeft(line, " "))
+		}
+		key = compact
+	}
+	buf.Write(key.Bytes())
+	typ := n.typ
+	switch n.kind {
+	case treeArray:
+		typ = "array"
+	case treeMap:
+		typ = "map"
+	}
+	fmt.Fprintf(buf, " (%s key)", typ)
+}
+
+// writeTrees writes a sequence of top-level values, one after the other.
+func writeTrees(buf *bytes.Buffer, nodes []treeNode) {
+	for i, n := range nodes {
+		if i != 0 {
+			buf.WriteByte('\n')
+		}
+		writeTree(buf, n, "")
+	}
+}